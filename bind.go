@@ -0,0 +1,204 @@
+package sqlrender
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// LengthUnit selects how BindVarchar measures string length.
+type LengthUnit int
+
+const (
+	// LengthRunes counts Unicode code points, matching character-based
+	// column types such as Postgres VARCHAR(n) or SQL Server NVARCHAR(n).
+	LengthRunes LengthUnit = iota
+	// LengthBytes counts UTF-8 bytes, matching byte-based limits such as
+	// Oracle VARCHAR2(n BYTE).
+	LengthBytes
+)
+
+// EmptyInPolicy selects how an empty list is rendered by `bind` and `in`.
+type EmptyInPolicy int
+
+const (
+	// EmptyInNull makes bind emit `(NULL)`, so `x IN (NULL)` matches nothing.
+	// Beware that `x NOT IN (NULL)` matches nothing either. `in` and `notIn`
+	// render `1 = 0` and `1 = 1`. This is the default.
+	EmptyInNull EmptyInPolicy = iota
+	// EmptyInFalse makes bind emit an empty subquery such as
+	// `(SELECT NULL WHERE 1 = 0)`, so `IN` is false and `NOT IN` is true.
+	// `in` and `notIn` behave as with EmptyInNull.
+	EmptyInFalse
+	// EmptyInError fails the render when bind, in or notIn receive an empty
+	// list, for teams that treat it as a caller bug.
+	EmptyInError
+)
+
+// emptyList renders an empty list bound with Bind according to the policy.
+func (qa *QueryArgs) emptyList() string {
+	switch qa.emptyIn {
+	case EmptyInFalse:
+		if qa.dialect == DialectOracle || qa.dialect == DialectMySQL {
+			return "(SELECT NULL FROM DUAL WHERE 1 = 0)"
+		}
+		return "(SELECT NULL WHERE 1 = 0)"
+	case EmptyInError:
+		panic("sqlrender: cannot bind an empty list")
+	default:
+		qa.warnf("empty list expanded to (NULL)")
+		return "(NULL)"
+	}
+}
+
+// NilPolicy selects how an untyped nil passed to bind and friends is handled.
+type NilPolicy int
+
+const (
+	// NilAsNull binds nil as SQL NULL. This is the default.
+	NilAsNull NilPolicy = iota
+	// NilAsError fails the render when an untyped nil is bound, including as
+	// a list element, so a missing value for a NOT NULL column surfaces at
+	// render time. Typed nils, such as a nil *int or an invalid
+	// sql.NullString, still bind as NULL, and so does the value of
+	// bindOrDefault, whose point is to replace NULL.
+	NilAsError
+)
+
+// BindIf binds value like Bind when cond is true and returns its
+// placeholder; when cond is false it binds nothing and returns an empty
+// string, so placeholder numbering is unaffected. Combined with `with` it
+// keeps optional filters terse:
+//
+//	{{ with bindIf .FilterStatus .Status }} AND status = {{ . }}{{ end }}
+func (qa *QueryArgs) BindIf(cond bool, value any) string {
+	if !cond {
+		return ""
+	}
+	return qa.Bind(value)
+}
+
+// BindOrDefault binds value and wraps the placeholder in COALESCE with the
+// supplied default rendered as an escaped literal, so nullable inputs fall back
+// to the default inside the database. The default must be a string, boolean,
+// number or nil.
+func (qa *QueryArgs) BindOrDefault(value any, literalDefault any) (string, error) {
+	def, err := scalarLiteral(qa.dialect, literalDefault)
+	if err != nil {
+		return "", err
+	}
+	var ph string
+	if value == nil {
+		ph = qa.addNullable(nil)
+	} else {
+		ph = qa.Bind(value)
+	}
+	return "COALESCE(" + ph + ", " + def + ")", nil
+}
+
+// BindVarchar binds a string value after checking it fits into a column of
+// maxLen characters, measured in the binder's LengthUnit (runes by default).
+// Oversized values return an error naming both lengths instead of surfacing
+// later as a driver truncation error. Nil values bind normally; values that
+// are not strings are rejected.
+func (qa *QueryArgs) BindVarchar(value any, maxLen int) (string, error) {
+	if isNil(value) {
+		return qa.Bind(value), nil
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("sqlrender: bindVarchar expects a string, got %T", value)
+	}
+
+	var n int
+	unit := "characters"
+	if qa.lengthUnit == LengthBytes {
+		n = len(v.String())
+		unit = "bytes"
+	} else {
+		n = utf8.RuneCountInString(v.String())
+	}
+	if n > maxLen {
+		return "", fmt.Errorf("sqlrender: value of %d %s exceeds maximum length %d", n, unit, maxLen)
+	}
+
+	return qa.Bind(value), nil
+}
+
+// castTypePattern accepts SQL type names such as `uuid`, `public.my_type`,
+// `timestamp with time zone`, `numeric(10, 2)` or `int[]`, and nothing that
+// could terminate the surrounding expression.
+var castTypePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*( [A-Za-z_][A-Za-z0-9_]*)*(\(\d+(, ?\d+)?\))?(\[\])?$`)
+
+func validateCastType(sqlType string) error {
+	if !castTypePattern.MatchString(sqlType) {
+		return fmt.Errorf("sqlrender: invalid cast type %q", sqlType)
+	}
+	return nil
+}
+
+// BindCast binds a single value with an explicit type cast: `$1::uuid` on
+// Postgres and `CAST(? AS type)` on every other dialect. sqlType is validated
+// against a strict type-name pattern so it cannot carry injected SQL. Lists
+// are rejected; use bindCastSlice to cast each element.
+func (qa *QueryArgs) BindCast(value any, sqlType string) (string, error) {
+	if err := validateCastType(sqlType); err != nil {
+		return "", err
+	}
+	if v := reflect.ValueOf(value); v.IsValid() && isList(v) {
+		return "", fmt.Errorf("sqlrender: bindCast expects a single value, got %T", value)
+	}
+
+	ph := qa.bindScalar(reflect.ValueOf(value))
+	if qa.dialect == DialectPostgres {
+		return ph + "::" + sqlType, nil
+	}
+	return "CAST(" + ph + " AS " + sqlType + ")", nil
+}
+
+// BindJSON marshals v with encoding/json and binds the result as a single
+// string argument, adding a `::jsonb` cast on Postgres. Marshal errors are
+// returned, so they surface as template execution errors.
+func (qa *QueryArgs) BindJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("sqlrender: bindJSON: %w", err)
+	}
+
+	ph := qa.add(string(b))
+	if qa.dialect == DialectPostgres {
+		return ph + "::jsonb", nil
+	}
+	return ph, nil
+}
+
+// BindCastSlice expands a slice like Bind but appends a Postgres `::type`
+// cast to every element placeholder, e.g. `($1::uuid, $2::uuid)`. It is only
+// available for Postgres; sqlType is validated against a strict type-name
+// pattern. An empty slice follows the renderer's EmptyInPolicy, like Bind.
+func (qa *QueryArgs) BindCastSlice(slice any, sqlType string) (string, error) {
+	if qa.dialect != DialectPostgres {
+		return "", fmt.Errorf("sqlrender: bindCastSlice is not supported for dialect %q", qa.dialect)
+	}
+	if err := validateCastType(sqlType); err != nil {
+		return "", err
+	}
+
+	v := reflect.ValueOf(slice)
+	if !v.IsValid() || !isList(v) {
+		return "", fmt.Errorf("sqlrender: bindCastSlice expects a slice or array, got %T", slice)
+	}
+	if v.Len() == 0 {
+		return qa.emptyList(), nil
+	}
+
+	placeholders := make([]string, v.Len())
+	for i := range placeholders {
+		placeholders[i] = qa.bindScalar(v.Index(i)) + "::" + sqlType
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")", nil
+}
@@ -0,0 +1,539 @@
+package sqlrender
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryArgsBindList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		arg      any
+		wantSQL  string
+		wantBind string
+		wantArgs []any
+	}{
+		{"slice", []int{1, 2, 3}, "$1, $2, $3", "($1, $2, $3)", []any{1, 2, 3}},
+		{"empty slice", []string{}, "", "(NULL)", nil},
+		{"scalar", 5, "$1", "$1", []any{5}},
+		{"byte slice", []byte("ab"), "$1", "$1", []any{[]byte("ab")}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(DialectPostgres)
+			if got := qa.BindList(tt.arg); got != tt.wantSQL {
+				t.Fatalf("bindList mismatch: got %q, want %q", got, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+			}
+			if got := NewQueryArgs(DialectPostgres).Bind(tt.arg); got != tt.wantBind {
+				t.Fatalf("bind mismatch: got %q, want %q", got, tt.wantBind)
+			}
+		})
+	}
+}
+
+func TestRendererBindList(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	out, args, err := r.FromString(
+		`SELECT * FROM t WHERE id = ANY(ARRAY[{{ bindList .IDs }}]) AND kind IN {{ bind .Kinds }}`,
+		map[string]any{"IDs": []int{4, 5}, "Kinds": []string{"a"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM t WHERE id = ANY(ARRAY[$1, $2]) AND kind IN ($3)`; out != want {
+		t.Fatalf("sql mismatch: got %q, want %q", out, want)
+	}
+	if want := []any{4, 5, "a"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsBindOrDefault(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+
+	got, err := qa.BindOrDefault(nil, "it's default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `COALESCE($1, 'it''s default')`; got != want {
+		t.Fatalf("placeholder mismatch: got %q, want %q", got, want)
+	}
+
+	got, err = qa.BindOrDefault(7, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `COALESCE($2, 10)`; got != want {
+		t.Fatalf("placeholder mismatch: got %q, want %q", got, want)
+	}
+
+	if want := []any{nil, 7}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+}
+
+func TestQueryArgsBindOrDefaultRejectsComplexDefault(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	if _, err := qa.BindOrDefault(1, []string{"a"}); err == nil {
+		t.Fatal("expected error for slice default")
+	}
+	if len(qa.args) != 0 {
+		t.Fatalf("expected nothing bound on error, got %v", qa.args)
+	}
+}
+
+func TestRendererBindOrDefault(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	sql, args, err := r.FromString(
+		`SELECT {{ bindOrDefault .Name "guest" }}`,
+		map[string]any{"Name": nil},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT COALESCE(?, 'guest')`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{nil}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsBindVarchar(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+
+	got, err := qa.BindVarchar("héllo", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "$1" {
+		t.Fatalf("placeholder mismatch: got %q, want %q", got, "$1")
+	}
+
+	if _, err := qa.BindVarchar("toolong", 5); err == nil {
+		t.Fatal("expected error for oversized string")
+	} else if !strings.Contains(err.Error(), "exceeds maximum length 5") {
+		t.Fatalf("error should mention the limit: %v", err)
+	}
+
+	if got, err := qa.BindVarchar(nil, 5); err != nil || got != "$2" {
+		t.Fatalf("nil should bind normally: got %q, %v", got, err)
+	}
+
+	if _, err := qa.BindVarchar(12, 5); err == nil {
+		t.Fatal("expected error for non-string value")
+	}
+
+	if want := []any{"héllo", nil}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+}
+
+func TestQueryArgsBindVarcharBytes(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectOracle)
+	qa.lengthUnit = LengthBytes
+
+	if _, err := qa.BindVarchar("héllo", 5); err == nil {
+		t.Fatal("expected error: héllo is 6 bytes")
+	}
+	if _, err := qa.BindVarchar("hello", 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRendererBindVarchar(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	if out := r.SetLengthUnit(LengthBytes); out != r {
+		t.Fatal("SetLengthUnit should return renderer instance")
+	}
+
+	sql, args, err := r.FromString(`VALUES ({{ bindVarchar .Code 3 }})`, map[string]any{"Code": "abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sql != "VALUES (?)" || !reflect.DeepEqual(args, []any{"abc"}) {
+		t.Fatalf("unexpected result: %q %v", sql, args)
+	}
+
+	if _, _, err := r.FromString(`VALUES ({{ bindVarchar .Code 3 }})`, map[string]any{"Code": "äbc"}); err == nil {
+		t.Fatal("expected error for oversized value")
+	}
+}
+
+func TestQueryArgsBindCast(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect Dialect
+		sqlType string
+		want    string
+	}{
+		{DialectPostgres, "uuid", "$1::uuid"},
+		{DialectPostgres, "jsonb", "$1::jsonb"},
+		{DialectMySQL, "CHAR", "CAST(? AS CHAR)"},
+		{DialectSQLServer, "nvarchar(36)", "CAST(@p1 AS nvarchar(36))"},
+		{DialectOracle, "NUMBER(10, 2)", "CAST(:1 AS NUMBER(10, 2))"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.dialect)+" "+tt.sqlType, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(tt.dialect)
+			got, err := qa.BindCast("v", tt.sqlType)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("cast mismatch: got %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(qa.args, []any{"v"}) {
+				t.Fatalf("args mismatch: got %v", qa.args)
+			}
+		})
+	}
+}
+
+func TestQueryArgsBindCastByteArray(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	if _, err := qa.BindCast([16]byte{1}, "uuid"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []any{make16(1)}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+}
+
+func TestQueryArgsBindCastErrors(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	if _, err := qa.BindCast("v", "uuid; DROP TABLE users"); err == nil {
+		t.Fatal("expected error for injected cast type")
+	}
+	if _, err := qa.BindCast([]int{1, 2}, "int"); err == nil {
+		t.Fatal("expected error for list value")
+	}
+	if len(qa.args) != 0 {
+		t.Fatalf("expected no args after errors, got %v", qa.args)
+	}
+}
+
+func TestRendererBindCast(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	sql, _, err := r.FromString(`SELECT * FROM t WHERE id = {{ bindCast .ID "uuid" }}`, map[string]any{"ID": "abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM t WHERE id = $1::uuid`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestQueryArgsBindCastSlice(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	got, err := qa.BindCastSlice([]string{"a", "b", "c"}, "uuid")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "($1::uuid, $2::uuid, $3::uuid)"; got != want {
+		t.Fatalf("cast list mismatch: got %q, want %q", got, want)
+	}
+	if len(qa.args) != 3 {
+		t.Fatalf("expected 3 args, got %v", qa.args)
+	}
+
+	got, err = qa.BindCastSlice([]float64{1.5}, "numeric(10, 2)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "($4::numeric(10, 2))"; got != want {
+		t.Fatalf("cast list mismatch: got %q, want %q", got, want)
+	}
+
+	qa = NewQueryArgs(DialectPostgres)
+	if _, err := qa.BindCastSlice([][16]byte{{1}, {2}}, "uuid"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []any{make16(1), make16(2)}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+}
+
+func TestRendererBindCastSliceEmpty(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `WHERE id IN {{ bindCastSlice .IDs "uuid" }}`
+	data := map[string]any{"IDs": []string{}}
+
+	tests := []struct {
+		name    string
+		policy  EmptyInPolicy
+		want    string
+		wantErr bool
+	}{
+		{"null", EmptyInNull, "WHERE id IN (NULL)", false},
+		{"false", EmptyInFalse, "WHERE id IN (SELECT NULL WHERE 1 = 0)", false},
+		{"error", EmptyInError, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewRenderer(DialectPostgres).SetEmptyInPolicy(tt.policy)
+			out, _, err := r.FromString(tmpl, data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Fatalf("sql mismatch: got %q, want %q", out, tt.want)
+			}
+		})
+	}
+
+	stmt, err := NewRenderer(DialectPostgres).SetWarningsEnabled(true).RenderString(tmpl, data, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"empty list expanded to (NULL)"}; !reflect.DeepEqual(stmt.Warnings(), want) {
+		t.Fatalf("warnings mismatch: got %v, want %v", stmt.Warnings(), want)
+	}
+}
+
+func TestQueryArgsBindCastSliceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		value   any
+		sqlType string
+	}{
+		{"mysql", DialectMySQL, []int{1}, "int"},
+		{"injection", DialectPostgres, []int{1}, "int); DROP TABLE users; --"},
+		{"empty type", DialectPostgres, []int{1}, ""},
+		{"scalar", DialectPostgres, 1, "int"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			qa := NewQueryArgs(tt.dialect)
+			if got, err := qa.BindCastSlice(tt.value, tt.sqlType); err == nil {
+				t.Fatalf("expected error, got %q", got)
+			}
+			if len(qa.args) != 0 {
+				t.Fatalf("expected nothing bound, got %v", qa.args)
+			}
+		})
+	}
+}
+
+func TestValidateCastType(t *testing.T) {
+	t.Parallel()
+
+	for _, ok := range []string{"uuid", "jsonb", "public.my_enum", "timestamp with time zone", "varchar(255)", "int[]", "CHAR"} {
+		if err := validateCastType(ok); err != nil {
+			t.Fatalf("expected %q to be valid: %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"", "1int", "int;", "text'", "int -- x", "int)::text"} {
+		if err := validateCastType(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestQueryArgsBindIf(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	if got := qa.BindIf(false, "skipped"); got != "" {
+		t.Fatalf("expected empty output when cond is false, got %q", got)
+	}
+	if len(qa.args) != 0 {
+		t.Fatalf("expected no args after skipped bind, got %v", qa.args)
+	}
+	if got := qa.BindIf(true, 0); got != "$1" {
+		t.Fatalf("placeholder mismatch: got %q, want %q", got, "$1")
+	}
+	if want := []any{0}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+}
+
+func TestRendererBindIfNumbering(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM t WHERE a = {{ bind .A }}` +
+		`{{ with bindIf .HasStatus .Status }} AND status = {{ . }}{{ end }}` +
+		`{{ with bindIf .HasOrg .Org }} AND org = {{ . }}{{ end }}`
+
+	r := NewRenderer(DialectSQLServer)
+	sql, args, err := r.FromString(tmpl, map[string]any{
+		"A": 1, "HasStatus": false, "Status": "", "HasOrg": true, "Org": 7,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM t WHERE a = @p1 AND org = @p2`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{1, 7}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsBindJSON(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]any{"b": []int{1, 2}, "a": "x"}
+
+	qa := NewQueryArgs(DialectPostgres)
+	got, err := qa.BindJSON(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "$1::jsonb"; got != want {
+		t.Fatalf("placeholder mismatch: got %q, want %q", got, want)
+	}
+	if want := []any{`{"a":"x","b":[1,2]}`}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+
+	qa = NewQueryArgs(DialectMySQL)
+	if got, err := qa.BindJSON(struct{ N int }{3}); err != nil || got != "?" {
+		t.Fatalf("mysql mismatch: got %q, %v", got, err)
+	}
+	if want := []any{`{"N":3}`}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+}
+
+func TestRendererBindJSONMarshalError(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	_, _, err := r.FromString(`INSERT INTO t (doc) VALUES ({{ bindJSON .Doc }})`, map[string]any{"Doc": make(chan int)})
+	if err == nil || !strings.Contains(err.Error(), "bindJSON") {
+		t.Fatalf("expected marshal error, got %v", err)
+	}
+}
+
+func TestRendererSetEmptyInPolicy(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM t WHERE id IN {{ bind .IDs }} AND {{ notIn "org" .Orgs }}`
+	data := map[string]any{"IDs": []int{}, "Orgs": []int(nil)}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		policy  EmptyInPolicy
+		want    string
+	}{
+		{"default", DialectPostgres, EmptyInNull, `SELECT * FROM t WHERE id IN (NULL) AND 1 = 1`},
+		{"false", DialectPostgres, EmptyInFalse, `SELECT * FROM t WHERE id IN (SELECT NULL WHERE 1 = 0) AND 1 = 1`},
+		{"false oracle", DialectOracle, EmptyInFalse, `SELECT * FROM t WHERE id IN (SELECT NULL FROM DUAL WHERE 1 = 0) AND 1 = 1`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewRenderer(tt.dialect).SetEmptyInPolicy(tt.policy)
+			sql, args, err := r.FromString(tmpl, data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sql != tt.want {
+				t.Fatalf("sql mismatch: got %q, want %q", sql, tt.want)
+			}
+			if len(args) != 0 {
+				t.Fatalf("expected no args, got %v", args)
+			}
+		})
+	}
+
+	r := NewRenderer(DialectPostgres).SetEmptyInPolicy(EmptyInError)
+	for _, tmpl := range []string{`{{ bind .IDs }}`, `{{ in "id" .IDs }}`, `{{ inChunked "id" .IDs 10 }}`, `{{ notIn "id" .Missing }}`} {
+		if _, _, err := r.FromString(tmpl, map[string]any{"IDs": []string{}}); err == nil || !strings.Contains(err.Error(), "empty list") {
+			t.Fatalf("expected empty list error for %s, got %v", tmpl, err)
+		}
+	}
+	if _, _, err := r.FromString(`{{ bind .ID }} {{ in "id" .IDs }}`, map[string]any{"ID": nil, "IDs": []int{1}}); err != nil {
+		t.Fatalf("non-empty input should render, got %v", err)
+	}
+}
+
+func TestRendererSetNilPolicy(t *testing.T) {
+	t.Parallel()
+
+	var missing *int
+	data := map[string]any{"Nil": nil, "Typed": missing, "Null": sql.NullString{}, "List": []any{1, nil}}
+
+	r := NewRenderer(DialectPostgres)
+	if _, args, err := r.FromString(`{{ bind .Nil }}`, data); err != nil || !reflect.DeepEqual(args, []any{nil}) {
+		t.Fatalf("default policy should bind nil: args %v, err %v", args, err)
+	}
+
+	r.SetNilPolicy(NilAsError)
+	for _, tmpl := range []string{`{{ bind .Nil }}`, `{{ bind .Missing }}`, `{{ bind .List }}`, `{{ bindCast .Nil "int" }}`} {
+		if _, _, err := r.FromString(tmpl, data); err == nil || !strings.Contains(err.Error(), "cannot bind nil") {
+			t.Fatalf("expected nil policy error for %s, got %v", tmpl, err)
+		}
+	}
+
+	out, args, err := r.FromString(`{{ bind .Typed }}, {{ bind .Null }}, {{ bindOrDefault .Nil "x" }}, {{ eqNullable "a" .Nil }}`, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `$1, $2, COALESCE($3, 'x'), "a" IS NULL`; out != want {
+		t.Fatalf("sql mismatch: got %q, want %q", out, want)
+	}
+	if want := []any{missing, sql.NullString{}, nil}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
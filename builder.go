@@ -0,0 +1,189 @@
+package sqlrender
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Builder assembles SELECT statements programmatically, for queries built from
+// optional filters where a template would be mostly conditionals. It binds
+// through QueryArgs and quotes through Identifier, so placeholder numbering
+// and dialect handling match the template path.
+type Builder struct {
+	renderer *Renderer
+	columns  []string
+	table    string
+	where    []condition
+	order    []OrderTerm
+	limit    *int
+}
+
+type condition struct {
+	sql  string
+	args []any
+}
+
+// NewBuilder returns an empty Builder that uses the default QueryArgs
+// configuration.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Builder returns an empty Builder whose binder is configured from the
+// renderer (identifier transformer and other options).
+func (r *Renderer) Builder() *Builder {
+	return &Builder{renderer: r}
+}
+
+// Select appends columns to the select list. Each column is quoted as an
+// identifier; `*` is passed through. With no columns the builder selects `*`.
+func (b *Builder) Select(cols ...string) *Builder {
+	b.columns = append(b.columns, cols...)
+	return b
+}
+
+// From sets the table to select from.
+func (b *Builder) From(table string) *Builder {
+	b.table = table
+	return b
+}
+
+// Where adds a condition. Every `?` in cond (outside single-quoted strings) is
+// replaced by the placeholder of the matching argument; slice arguments expand
+// like bind does. Conditions are joined with AND and parenthesized when there
+// is more than one.
+func (b *Builder) Where(cond string, args ...any) *Builder {
+	b.where = append(b.where, condition{sql: cond, args: args})
+	return b
+}
+
+// And is an alias of Where that reads naturally when chaining conditions.
+func (b *Builder) And(cond string, args ...any) *Builder {
+	return b.Where(cond, args...)
+}
+
+// OrderBy appends ORDER BY terms, validated like the `orderBy` helper.
+func (b *Builder) OrderBy(terms ...OrderTerm) *Builder {
+	b.order = append(b.order, terms...)
+	return b
+}
+
+// Limit caps the number of returned rows. The limit is bound as an argument.
+func (b *Builder) Limit(n int) *Builder {
+	b.limit = &n
+	return b
+}
+
+// Build renders the statement for the dialect and returns the SQL and bound
+// arguments. Invalid identifiers, sort directions and placeholder/argument
+// count mismatches are reported as errors.
+func (b *Builder) Build(dialect Dialect) (sql string, args []any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			sql, args, err = "", nil, fmt.Errorf("%v", r)
+		}
+	}()
+
+	if b.table == "" {
+		return "", nil, fmt.Errorf("sqlrender: builder requires a table")
+	}
+
+	qa := NewQueryArgs(dialect)
+	if b.renderer != nil {
+		qa = b.renderer.newQueryArgs(dialect)
+	}
+
+	// SQL Server only accepts OFFSET/FETCH after an ORDER BY, so without one
+	// the limit is expressed as TOP in the select position instead.
+	useTop := dialect == DialectSQLServer && len(b.order) == 0
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	if b.limit != nil {
+		if top := qa.Top(*b.limit, useTop); top != "" {
+			sb.WriteString(top + " ")
+		}
+	}
+
+	cols := make([]string, len(b.columns))
+	for i, col := range b.columns {
+		if col == "*" {
+			cols[i] = col
+			continue
+		}
+		cols[i] = qa.mustIdentifier(col)
+	}
+	if len(cols) == 0 {
+		cols = []string{"*"}
+	}
+	sb.WriteString(strings.Join(cols, ", "))
+	sb.WriteString(" FROM " + qa.mustIdentifier(b.table))
+
+	if len(b.where) > 0 {
+		conds := make([]string, len(b.where))
+		for i, c := range b.where {
+			expanded, err := qa.expandMarkers(c.sql, c.args)
+			if err != nil {
+				return "", nil, err
+			}
+			if len(b.where) > 1 {
+				expanded = "(" + expanded + ")"
+			}
+			conds[i] = expanded
+		}
+		sb.WriteString(" WHERE " + strings.Join(conds, " AND "))
+	}
+
+	// Legacy Oracle has no trailing limit, so the query is wrapped instead and
+	// ordered through ROW_NUMBER().
+	if b.limit != nil && dialect == DialectOracle && qa.legacyOracle {
+		var order string
+		if len(b.order) > 0 {
+			order = qa.OrderBy(b.order)
+		}
+		wrapped, err := qa.Paginate(sb.String(), order, *b.limit, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		return wrapped, qa.args, nil
+	}
+
+	if len(b.order) > 0 {
+		sb.WriteString(" ORDER BY " + qa.OrderBy(b.order))
+	}
+
+	if b.limit != nil {
+		if limit := qa.Limit(*b.limit, useTop); limit != "" {
+			sb.WriteString(" " + limit)
+		}
+	}
+
+	return sb.String(), qa.args, nil
+}
+
+// expandMarkers replaces each `?` outside single-quoted strings with the
+// placeholder for the corresponding argument.
+func (qa *QueryArgs) expandMarkers(cond string, args []any) (string, error) {
+	var sb strings.Builder
+	next := 0
+	inString := false
+	for _, r := range cond {
+		switch {
+		case r == '\'':
+			inString = !inString
+		case r == '?' && !inString:
+			if next >= len(args) {
+				return "", fmt.Errorf("sqlrender: condition %q has more placeholders than arguments (%d)", cond, len(args))
+			}
+			sb.WriteString(qa.Bind(args[next]))
+			next++
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	if next != len(args) {
+		return "", fmt.Errorf("sqlrender: condition %q has %d placeholders but %d arguments", cond, next, len(args))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,144 @@
+package sqlrender
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuilderBuild(t *testing.T) {
+	t.Parallel()
+
+	sql, args, err := NewBuilder().
+		Select("id", "name").
+		From("public.users").
+		Where("status = ?", "active").
+		And("org_id IN ? OR owner = ?", []int{1, 2}, 9).
+		OrderBy(OrderTerm{Column: "name", Direction: SortAsc}).
+		Limit(10).
+		Build(DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `SELECT "id", "name" FROM "public"."users" WHERE (status = $1) AND (org_id IN ($2, $3) OR owner = $4) ORDER BY "name" ASC LIMIT $5`
+	if sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if wantArgs := []any{"active", 1, 2, 9, 10}; !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args mismatch: got %v, want %v", args, wantArgs)
+	}
+}
+
+func TestBuilderOmitsEmptyWhere(t *testing.T) {
+	t.Parallel()
+
+	sql, args, err := NewBuilder().From("users").Build(DialectMySQL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT * FROM `users`"; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if len(args) != 0 {
+		t.Fatalf("expected no args, got %v", args)
+	}
+}
+
+func TestBuilderSingleWhereNotParenthesized(t *testing.T) {
+	t.Parallel()
+
+	sql, _, err := NewBuilder().Select("*").From("users").Where("name = '?' AND id = ?", 1).Build(DialectOracle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM "users" WHERE name = '?' AND id = :1`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestBuilderSQLServerLimit(t *testing.T) {
+	t.Parallel()
+
+	sql, args, err := NewBuilder().From("users").Where("org = ?", 3).Limit(5).Build(DialectSQLServer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT TOP (@p1) * FROM [users] WHERE org = @p2`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{5, 3}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	sql, _, err = NewBuilder().From("users").OrderBy(OrderTerm{"id", SortDesc}).Limit(5).Build(DialectSQLServer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM [users] ORDER BY [id] DESC OFFSET 0 ROWS FETCH NEXT @p1 ROWS ONLY`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestBuilderLegacyOracleLimit(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectOracle).SetLegacyOracle(true)
+	sql, args, err := r.Builder().From("users").Where("org = ?", 3).OrderBy(OrderTerm{"id", SortDesc}).Limit(5).Build(DialectOracle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `SELECT * FROM (SELECT q.*, ROW_NUMBER() OVER (ORDER BY "id" DESC) rn FROM (SELECT * FROM "users" WHERE org = :1) q) WHERE rn BETWEEN :2 AND :3 ORDER BY rn`
+	if sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{3, 1, 5}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		builder *Builder
+		wantErr string
+	}{
+		{"missing table", NewBuilder().Select("id"), "requires a table"},
+		{"invalid column", NewBuilder().Select("id;").From("users"), "invalid identifier"},
+		{"too few args", NewBuilder().From("users").Where("a = ? AND b = ?", 1), "more placeholders"},
+		{"too many args", NewBuilder().From("users").Where("a = ?", 1, 2), "1 placeholders but 2 arguments"},
+		{"invalid direction", NewBuilder().From("users").OrderBy(OrderTerm{"id", "up"}), "invalid sort direction"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := tt.builder.Build(DialectPostgres)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRendererBuilderUsesRendererConfig(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres).SetIdentifierTransformer(func(name string) string {
+		if name == "users" {
+			return "app_users"
+		}
+		return name
+	})
+
+	sql, _, err := r.Builder().Select("id").From("users").Build(DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT "id" FROM "app_users"`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
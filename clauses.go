@@ -0,0 +1,516 @@
+package sqlrender
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SortDirection is the whitelisted set of directions accepted by OrderBy.
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "ASC"
+	SortDesc SortDirection = "DESC"
+)
+
+// OrderTerm is a single ORDER BY entry. Column is validated and quoted like any
+// other identifier, while Direction must be one of the SortDirection values.
+type OrderTerm struct {
+	Column    string
+	Direction SortDirection
+}
+
+// OrderBy renders the supplied terms as a comma-separated ORDER BY list
+// (without the keyword). Directions are matched case-insensitively against
+// SortAsc and SortDesc; any other value, like an invalid column, triggers a
+// panic so user-controlled sort parameters can never reach the SQL verbatim.
+func (qa *QueryArgs) OrderBy(spec []OrderTerm) string {
+	terms := make([]string, len(spec))
+	for i, term := range spec {
+		column := qa.mustIdentifier(term.Column)
+
+		dir := SortDirection(strings.ToUpper(string(term.Direction)))
+		if dir != SortAsc && dir != SortDesc {
+			panic(fmt.Sprintf("sqlrender: invalid sort direction %q", term.Direction))
+		}
+
+		terms[i] = column + " " + string(dir)
+	}
+	return strings.Join(terms, ", ")
+}
+
+// Explain returns the dialect-specific keyword prefix that asks the database
+// for a query plan, e.g. `EXPLAIN ANALYZE` on Postgres. Dialects that cannot
+// express the request as a prefix return an error.
+//
+// SQL Server has no EXPLAIN statement: plans are requested by running
+// `SET SHOWPLAN_ALL ON` (estimated plan) or `SET STATISTICS PROFILE ON`
+// (actual plan) as a separate batch before the query, so Explain always
+// returns an error for DialectSQLServer.
+func (qa *QueryArgs) Explain(analyze bool) (string, error) {
+	switch qa.dialect {
+	case DialectPostgres, DialectMySQL:
+		if analyze {
+			return "EXPLAIN ANALYZE", nil
+		}
+		return "EXPLAIN", nil
+	case DialectSQLite:
+		if analyze {
+			return "", fmt.Errorf("sqlrender: EXPLAIN ANALYZE is not supported for dialect %q", qa.dialect)
+		}
+		return "EXPLAIN QUERY PLAN", nil
+	case DialectOracle:
+		if analyze {
+			return "", fmt.Errorf("sqlrender: EXPLAIN ANALYZE is not supported for dialect %q", qa.dialect)
+		}
+		return "EXPLAIN PLAN FOR", nil
+	case DialectSQLServer:
+		return "", fmt.Errorf("sqlrender: dialect %q has no EXPLAIN prefix; run SET SHOWPLAN_ALL ON in a separate batch instead", qa.dialect)
+	default:
+		if analyze {
+			return "", fmt.Errorf("sqlrender: EXPLAIN ANALYZE is not supported for dialect %q", qa.dialect)
+		}
+		return "EXPLAIN", nil // Snowflake
+	}
+}
+
+// Top renders the select-position row limit. It only produces output for SQL
+// Server inside a subquery, where `TOP (n)` replaces the trailing
+// OFFSET/FETCH form because SQL Server rejects OFFSET/FETCH in derived tables
+// without an ORDER BY. Everywhere else it returns an empty string.
+//
+// Top and Limit are meant to be used as a pair with the same subquery flag so
+// that exactly one of them emits a clause (and binds n) for any dialect:
+//
+//	SELECT {{ top .N true }} id FROM users {{ limit .N true }}
+func (qa *QueryArgs) Top(n any, subquery bool) string {
+	if qa.dialect != DialectSQLServer || !subquery {
+		return ""
+	}
+	return "TOP (" + qa.Bind(n) + ")"
+}
+
+// Limit renders the trailing row limit for the dialect: `LIMIT n` for most
+// engines, `FETCH FIRST n ROWS ONLY` for Oracle and
+// `OFFSET 0 ROWS FETCH NEXT n ROWS ONLY` for SQL Server (which requires an
+// ORDER BY). For SQL Server subqueries it returns an empty string and leaves
+// the limit to Top. Oracle before 12c has no trailing form, so with the
+// renderer's legacy Oracle mode enabled Limit panics and points to Paginate.
+func (qa *QueryArgs) Limit(n any, subquery bool) string {
+	switch qa.dialect {
+	case DialectSQLServer:
+		if subquery {
+			return ""
+		}
+		return "OFFSET 0 ROWS FETCH NEXT " + qa.Bind(n) + " ROWS ONLY"
+	case DialectOracle:
+		if qa.legacyOracle {
+			panic("sqlrender: limit is not available in legacy Oracle mode; wrap the query with paginate")
+		}
+		return "FETCH FIRST " + qa.Bind(n) + " ROWS ONLY"
+	default:
+		return "LIMIT " + qa.Bind(n) // Postgres, MySQL, SQLite, Snowflake
+	}
+}
+
+// Paginate returns query restricted to limit rows after skipping offset,
+// ordered by orderBy (an already rendered list such as the output of
+// orderBy, without the keyword; it may be blank):
+//
+//   - Postgres, MySQL, SQLite, Snowflake: `query ORDER BY ... LIMIT n OFFSET m`
+//   - SQL Server and Oracle 12c+: `query ORDER BY ... OFFSET m ROWS FETCH NEXT
+//     n ROWS ONLY`, ordering by `(SELECT NULL)` on SQL Server when orderBy is
+//     blank since it requires an ORDER BY
+//   - Oracle in legacy mode (SetLegacyOracle), which lacks OFFSET/FETCH:
+//     `SELECT * FROM (SELECT q.*, ROW_NUMBER() OVER (ORDER BY ...) rn FROM
+//     (query) q) WHERE rn BETWEEN lo AND hi ORDER BY rn`, binding the 1-based
+//     row numbers; the result carries the extra rn column
+//
+// Because the legacy form wraps the whole statement, pass the complete query
+// rather than emitting Paginate as a trailing clause. limit must be positive
+// and offset non-negative.
+func (qa *QueryArgs) Paginate(query, orderBy string, limit, offset int) (string, error) {
+	if limit < 1 || offset < 0 {
+		return "", fmt.Errorf("sqlrender: paginate needs a positive limit and a non-negative offset, got %d and %d", limit, offset)
+	}
+	query, orderBy = strings.TrimSpace(query), strings.TrimSpace(orderBy)
+
+	switch {
+	case qa.dialect == DialectOracle && qa.legacyOracle:
+		if orderBy == "" {
+			orderBy = "NULL"
+		}
+		return "SELECT * FROM (SELECT q.*, ROW_NUMBER() OVER (ORDER BY " + orderBy + ") rn FROM (" + query + ") q)" +
+			" WHERE rn BETWEEN " + qa.Bind(offset+1) + " AND " + qa.Bind(offset+limit) + " ORDER BY rn", nil
+	case qa.dialect == DialectOracle || qa.dialect == DialectSQLServer:
+		if orderBy == "" && qa.dialect == DialectSQLServer {
+			orderBy = "(SELECT NULL)"
+		}
+		if orderBy != "" {
+			query += " ORDER BY " + orderBy
+		}
+		return query + " OFFSET " + qa.Bind(offset) + " ROWS FETCH NEXT " + qa.Bind(limit) + " ROWS ONLY", nil
+	default:
+		if orderBy != "" {
+			query += " ORDER BY " + orderBy
+		}
+		return query + " LIMIT " + qa.Bind(limit) + " OFFSET " + qa.Bind(offset), nil
+	}
+}
+
+// Set renders an UPDATE assignment list (`"a" = $1, "b" = $2`) from the map,
+// quoting each key as an identifier and binding its value. Keys are emitted in
+// sorted order so the same map always yields the same SQL. An empty map
+// returns an empty string; invalid keys panic like Identifier.
+func (qa *QueryArgs) Set(assignments map[string]any) string {
+	parts := make([]string, 0, len(assignments))
+	for _, key := range sortedKeys(assignments) {
+		column := qa.mustIdentifier(key)
+		parts = append(parts, column+" = "+qa.Bind(assignments[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Union combines already-rendered SELECT statements with UNION (or UNION ALL
+// when all is true). Fragments are joined as-is, without parentheses, since
+// SQLite rejects parenthesized compound members; blank fragments are dropped.
+// Because all fragments are rendered against the same binder, their
+// placeholders stay sequential across the combined query.
+func (qa *QueryArgs) Union(all bool, selects ...string) string {
+	op := " UNION "
+	if all {
+		op = " UNION ALL "
+	}
+	return strings.Join(nonBlank(selects), op)
+}
+
+// nonBlank returns the fragments that contain more than whitespace, trimmed.
+func nonBlank(fragments []string) []string {
+	out := make([]string, 0, len(fragments))
+	for _, f := range fragments {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// DistinctOn renders the Postgres `DISTINCT ON ("a", "b")` select modifier.
+// Other dialects have no equivalent and return an error: there the query has
+// to be rewritten with ROW_NUMBER() OVER (PARTITION BY ...) and a filter on
+// the row number.
+func (qa *QueryArgs) DistinctOn(columns ...string) (string, error) {
+	if qa.dialect != DialectPostgres {
+		return "", fmt.Errorf("sqlrender: DISTINCT ON is not supported for dialect %q; use a ROW_NUMBER() window instead", qa.dialect)
+	}
+	if len(columns) == 0 {
+		return "", fmt.Errorf("sqlrender: DISTINCT ON requires at least one column")
+	}
+
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		q, err := qa.identifier(col)
+		if err != nil {
+			return "", err
+		}
+		quoted[i] = q
+	}
+	return "DISTINCT ON (" + strings.Join(quoted, ", ") + ")", nil
+}
+
+// Columns quotes every name as an identifier and joins them with ", ", for
+// SELECT and GROUP BY lists built from column names. Nothing is bound and no
+// names renders an empty string; an invalid name panics like Identifier.
+func (qa *QueryArgs) Columns(names ...string) string {
+	return qa.JoinIdentifiers(", ", names...)
+}
+
+// IdentifierList is Columns for a slice, such as a []string field ranged
+// over or passed straight from template data.
+func (qa *QueryArgs) IdentifierList(names []string) string {
+	return qa.Columns(names...)
+}
+
+// IdentifierWhitelist quotes and joins names like IdentifierList, for
+// untrusted input such as a user-chosen set of columns. Every name must be a
+// key of allowed mapped to true, compared exactly; the first one that is not
+// (or that fails identifier validation) is reported as an error rather than
+// a panic, so handlers can turn it into a client error.
+func (qa *QueryArgs) IdentifierWhitelist(names []string, allowed map[string]bool) (string, error) {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		if !allowed[name] {
+			return "", fmt.Errorf("sqlrender: identifier %q is not allowed", name)
+		}
+		q, err := qa.identifier(name)
+		if err != nil {
+			return "", err
+		}
+		quoted[i] = q
+	}
+	return strings.Join(quoted, ", "), nil
+}
+
+// JoinIdentifiers joins identifiers with sep. Items may already be quoted for
+// the dialect (for example the output of identifier); they still go through
+// identifier validation, so arbitrary SQL cannot be spliced in. It backs the
+// `csv` template func.
+func (qa *QueryArgs) JoinIdentifiers(sep string, items ...string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = qa.mustIdentifier(item)
+	}
+	return strings.Join(quoted, sep)
+}
+
+// aggregates lists the functions Agg accepts.
+var aggregates = map[string]bool{"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true}
+
+// Agg renders an aggregate over a quoted column, such as `COUNT(DISTINCT
+// "user_id")` or `SUM("amount")`. fn is matched case-insensitively against
+// COUNT, SUM, AVG, MIN and MAX and emitted in upper case; anything else
+// panics, so a template cannot smuggle an arbitrary function call in. The
+// column `*` is accepted for COUNT without DISTINCT only.
+func (qa *QueryArgs) Agg(fn, column string, distinct bool) string {
+	name := strings.ToUpper(fn)
+	if !aggregates[name] {
+		panic(fmt.Sprintf("sqlrender: unsupported aggregate function %q", fn))
+	}
+
+	var arg string
+	if column == "*" {
+		if name != "COUNT" || distinct {
+			panic(fmt.Sprintf("sqlrender: * is only valid in COUNT(*), not in %s with distinct=%t", name, distinct))
+		}
+		arg = "*"
+	} else {
+		arg = qa.mustIdentifier(column)
+	}
+
+	if distinct {
+		arg = "DISTINCT " + arg
+	}
+	return name + "(" + arg + ")"
+}
+
+// Where joins the non-blank conditions with AND and prefixes the result with
+// WHERE, returning an empty string when every condition is blank. With more
+// than one condition each is parenthesized so an OR inside one fragment cannot
+// leak into its neighbours.
+//
+// Conditions are normally strings, but zero values of any type (nil, false,
+// 0) are treated as blank so the `and .Filter (printf ...)` idiom works for
+// optional filters; any other non-string value is an error.
+func (qa *QueryArgs) Where(conds ...any) (string, error) {
+	return joinConditions("WHERE ", " AND ", conds)
+}
+
+// OrWhere is like Where but joins the conditions with OR.
+func (qa *QueryArgs) OrWhere(conds ...any) (string, error) {
+	return joinConditions("WHERE ", " OR ", conds)
+}
+
+func joinConditions(prefix, op string, conds []any) (string, error) {
+	parts, err := conditionStrings(conds)
+	if err != nil {
+		return "", err
+	}
+
+	switch len(parts) {
+	case 0:
+		return "", nil
+	case 1:
+		return prefix + parts[0], nil
+	}
+	return prefix + "(" + strings.Join(parts, ")"+op+"(") + ")", nil
+}
+
+// conditionStrings returns the trimmed non-blank conditions, treating zero
+// values of any type as blank.
+func conditionStrings(conds []any) ([]string, error) {
+	strs := make([]string, 0, len(conds))
+	for _, c := range conds {
+		switch v := c.(type) {
+		case string:
+			strs = append(strs, v)
+		default:
+			if c != nil && !reflect.ValueOf(c).IsZero() {
+				return nil, fmt.Errorf("sqlrender: condition must be a string, got %T", c)
+			}
+		}
+	}
+	return nonBlank(strs), nil
+}
+
+// And combines already-rendered predicates into one parenthesized group,
+// `(a AND b)`, so the result can be negated or nested without precedence
+// surprises. Blank predicates (and zero values, as for Where) are dropped;
+// an operand that itself contains a top-level AND or OR is parenthesized. A
+// single remaining predicate is returned without the outer group and none
+// renders nothing. It backs the `sqlAnd` template func.
+func (qa *QueryArgs) And(conds ...any) (string, error) {
+	return groupConditions(" AND ", conds)
+}
+
+// Or is like And but joins the predicates with OR. It backs `sqlOr`.
+func (qa *QueryArgs) Or(conds ...any) (string, error) {
+	return groupConditions(" OR ", conds)
+}
+
+// Not negates an already-rendered predicate as `(NOT (a))`. A blank predicate
+// renders nothing, so negating an omitted filter omits it too. It backs
+// `sqlNot`.
+func (qa *QueryArgs) Not(cond any) (string, error) {
+	parts, err := conditionStrings([]any{cond})
+	if err != nil || len(parts) == 0 {
+		return "", err
+	}
+	return "(NOT (" + parts[0] + "))", nil
+}
+
+func groupConditions(op string, conds []any) (string, error) {
+	parts, err := conditionStrings(conds)
+	if err != nil || len(parts) == 0 {
+		return "", err
+	}
+	for i, p := range parts {
+		if hasTopLevelBoolOp(p) {
+			parts[i] = "(" + p + ")"
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return "(" + strings.Join(parts, op) + ")", nil
+}
+
+// hasTopLevelBoolOp reports whether s contains an AND or OR keyword outside
+// parentheses, literals and quoted identifiers. It errs on the side of true
+// (e.g. for BETWEEN ... AND), which only costs a redundant pair of
+// parentheses.
+func hasTopLevelBoolOp(s string) bool {
+	depth := 0
+	for _, tok := range scanSQL(s, DialectPostgres) {
+		if tok.kind != tokenText {
+			continue
+		}
+		word := 0
+		for i := 0; i <= len(tok.text); i++ {
+			if i < len(tok.text) && isWordByte(tok.text[i]) {
+				continue
+			}
+			if w := tok.text[word:i]; depth == 0 && (strings.EqualFold(w, "AND") || strings.EqualFold(w, "OR")) {
+				return true
+			}
+			word = i + 1
+			if i < len(tok.text) {
+				switch tok.text[i] {
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+			}
+		}
+	}
+	return false
+}
+
+// CTE is one named part of a WITH clause. SQL is the already-rendered body,
+// usually produced with the same binder so its placeholders continue the
+// statement's numbering.
+type CTE struct {
+	Name    string
+	Columns []string
+	SQL     string
+}
+
+// NewCTE returns a CTE part; it backs the `cte` template func.
+func NewCTE(name, sql string, columns ...string) CTE {
+	return CTE{Name: name, Columns: columns, SQL: sql}
+}
+
+// With renders `WITH "a" AS (...), "b" ("x", "y") AS (...)` from parts in
+// order, quoting names and column lists. It returns an empty string when
+// there are no parts, so a template can always emit it, and an error when a
+// part has a blank body. The template func is `withCTE`, as `with` is a
+// text/template keyword.
+func (qa *QueryArgs) With(parts ...CTE) (string, error) {
+	return qa.with("WITH ", parts)
+}
+
+// WithRecursive is like With but marks the clause recursive, so a part may
+// reference itself: `WITH RECURSIVE ...` on Postgres, MySQL, SQLite and
+// Snowflake. SQL Server and Oracle reject the keyword and detect recursion
+// on their own, so they get a plain `WITH`; Oracle additionally requires a
+// column list on recursive parts. The template func is `withRecursiveCTE`.
+func (qa *QueryArgs) WithRecursive(parts ...CTE) (string, error) {
+	keyword := "WITH RECURSIVE "
+	if qa.dialect == DialectSQLServer || qa.dialect == DialectOracle {
+		keyword = "WITH "
+	}
+	return qa.with(keyword, parts)
+}
+
+func (qa *QueryArgs) with(keyword string, parts []CTE) (string, error) {
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	rendered := make([]string, len(parts))
+	for i, part := range parts {
+		name, err := qa.identifier(part.Name)
+		if err != nil {
+			return "", err
+		}
+		body := strings.TrimSpace(part.SQL)
+		if body == "" {
+			return "", fmt.Errorf("sqlrender: CTE %q has an empty body", part.Name)
+		}
+
+		if len(part.Columns) > 0 {
+			cols := make([]string, len(part.Columns))
+			for j, c := range part.Columns {
+				if cols[j], err = qa.identifier(c); err != nil {
+					return "", err
+				}
+			}
+			name += " (" + strings.Join(cols, ", ") + ")"
+		}
+		rendered[i] = name + " AS (" + body + ")"
+	}
+	return keyword + strings.Join(rendered, ", "), nil
+}
+
+// Returning renders the clause that returns columns from an INSERT, UPDATE
+// or DELETE: `RETURNING "id", "created_at"` on Postgres, SQLite and Oracle
+// (which additionally needs an INTO list of out binds) and
+// `OUTPUT INSERTED.[id], INSERTED.[created_at]` on SQL Server, where the
+// clause goes before VALUES or WHERE instead of at the end. MySQL and
+// Snowflake have no equivalent and return an error.
+func (qa *QueryArgs) Returning(columns ...string) (string, error) {
+	if len(columns) == 0 {
+		return "", fmt.Errorf("sqlrender: returning requires at least one column")
+	}
+
+	var prefix, clause string
+	switch qa.dialect {
+	case DialectPostgres, DialectSQLite, DialectOracle:
+		clause = "RETURNING "
+	case DialectSQLServer:
+		clause, prefix = "OUTPUT ", "INSERTED."
+	default:
+		return "", fmt.Errorf("sqlrender: returning is not supported for dialect %q", qa.dialect)
+	}
+
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		col, err := qa.identifier(c)
+		if err != nil {
+			return "", err
+		}
+		quoted[i] = prefix + col
+	}
+	return clause + strings.Join(quoted, ", "), nil
+}
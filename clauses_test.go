@@ -0,0 +1,842 @@
+package sqlrender
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryArgsOrderBy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		spec    []OrderTerm
+		want    string
+	}{
+		{"postgres", DialectPostgres, []OrderTerm{{"created_at", SortDesc}, {"id", SortAsc}}, `"created_at" DESC, "id" ASC`},
+		{"sqlserver lowercase", DialectSQLServer, []OrderTerm{{"u.name", "desc"}}, `[u].[name] DESC`},
+		{"mysql", DialectMySQL, []OrderTerm{{"name", "Asc"}}, "`name` ASC"},
+		{"empty", DialectPostgres, nil, ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			qa := NewQueryArgs(tt.dialect)
+			if got := qa.OrderBy(tt.spec); got != tt.want {
+				t.Fatalf("order by mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryArgsOrderByInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		term OrderTerm
+	}{
+		{"direction", OrderTerm{"id", "DESC; DROP TABLE users"}},
+		{"empty direction", OrderTerm{"id", ""}},
+		{"column", OrderTerm{"id desc", SortAsc}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			qa := NewQueryArgs(DialectPostgres)
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatal("expected panic for invalid order term")
+				}
+			}()
+			qa.OrderBy([]OrderTerm{tt.term})
+		})
+	}
+}
+
+func TestRendererOrderBy(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	sql, args, err := r.FromString(
+		`SELECT * FROM users ORDER BY {{ orderBy .Sort }}`,
+		map[string]any{"Sort": []OrderTerm{{Column: "name", Direction: SortDesc}}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM users ORDER BY "name" DESC`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if len(args) != 0 {
+		t.Fatalf("expected no args, got %v", args)
+	}
+
+	_, _, err = r.FromString(
+		`ORDER BY {{ orderBy .Sort }}`,
+		map[string]any{"Sort": []OrderTerm{{Column: "name", Direction: "sideways"}}},
+	)
+	if err == nil {
+		t.Fatal("expected error for invalid direction")
+	}
+}
+
+func TestQueryArgsExplain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		analyze bool
+		want    string
+		wantErr bool
+	}{
+		{"postgres", DialectPostgres, false, "EXPLAIN", false},
+		{"postgres analyze", DialectPostgres, true, "EXPLAIN ANALYZE", false},
+		{"mysql", DialectMySQL, false, "EXPLAIN", false},
+		{"mysql analyze", DialectMySQL, true, "EXPLAIN ANALYZE", false},
+		{"sqlite", DialectSQLite, false, "EXPLAIN QUERY PLAN", false},
+		{"oracle", DialectOracle, false, "EXPLAIN PLAN FOR", false},
+		{"oracle analyze", DialectOracle, true, "", true},
+		{"sqlserver", DialectSQLServer, false, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			qa := NewQueryArgs(tt.dialect)
+			got, err := qa.Explain(tt.analyze)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("explain mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererExplain(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	sql, _, err := r.FromString(`{{ explain true }} SELECT 1`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "EXPLAIN ANALYZE SELECT 1"; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+
+	if _, _, err := r.FromStringWithDialect(`{{ explain false }} SELECT 1`, nil, DialectSQLServer); err == nil {
+		t.Fatal("expected error for sqlserver explain")
+	}
+}
+
+func TestQueryArgsTopAndLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		dialect   Dialect
+		subquery  bool
+		wantTop   string
+		wantLimit string
+	}{
+		{"postgres", DialectPostgres, false, "", "LIMIT $1"},
+		{"postgres subquery", DialectPostgres, true, "", "LIMIT $1"},
+		{"mysql", DialectMySQL, false, "", "LIMIT ?"},
+		{"oracle", DialectOracle, false, "", "FETCH FIRST :1 ROWS ONLY"},
+		{"sqlserver", DialectSQLServer, false, "", "OFFSET 0 ROWS FETCH NEXT @p1 ROWS ONLY"},
+		{"sqlserver subquery", DialectSQLServer, true, "TOP (@p1)", ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			qa := NewQueryArgs(tt.dialect)
+			if got := qa.Top(10, tt.subquery); got != tt.wantTop {
+				t.Fatalf("top mismatch: got %q, want %q", got, tt.wantTop)
+			}
+			if got := qa.Limit(10, tt.subquery); got != tt.wantLimit {
+				t.Fatalf("limit mismatch: got %q, want %q", got, tt.wantLimit)
+			}
+			if len(qa.args) != 1 || qa.args[0] != 10 {
+				t.Fatalf("expected exactly one bound arg, got %v", qa.args)
+			}
+		})
+	}
+}
+
+func TestRendererTopInSubquery(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM (SELECT {{ top .N true }} id FROM users WHERE org = {{ bind .Org }} {{ limit .N true }}) AS u`
+
+	r := NewRenderer(DialectSQLServer)
+	sql, args, err := r.FromString(tmpl, map[string]any{"N": 5, "Org": 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM (SELECT TOP (@p1) id FROM users WHERE org = @p2 ) AS u`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{5, 2}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	sql, args, err = r.FromStringWithDialect(tmpl, map[string]any{"N": 5, "Org": 2}, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM (SELECT  id FROM users WHERE org = $1 LIMIT $2) AS u`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{2, 5}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsSet(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	got := qa.Set(map[string]any{"name": "bob", "age": 30, "email": nil})
+	if want := `"age" = $1, "email" = $2, "name" = $3`; got != want {
+		t.Fatalf("set mismatch: got %q, want %q", got, want)
+	}
+	if want := []any{30, nil, "bob"}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+
+	if got := NewQueryArgs(DialectPostgres).Set(nil); got != "" {
+		t.Fatalf("expected empty set for nil map, got %q", got)
+	}
+}
+
+func TestRendererSetIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]any{}
+	for i := 0; i < 20; i++ {
+		values[fmt.Sprintf("col_%02d", i)] = i
+	}
+
+	r := NewRenderer(DialectPostgres)
+	first, firstArgs, err := r.FromString(`UPDATE t SET {{ set .Values }}`, map[string]any{"Values": values})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		sql, args, err := r.FromString(`UPDATE t SET {{ set .Values }}`, map[string]any{"Values": values})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sql != first || !reflect.DeepEqual(args, firstArgs) {
+			t.Fatalf("render %d differs: got %q %v, want %q %v", i, sql, args, first, firstArgs)
+		}
+	}
+}
+
+func TestQueryArgsUnion(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	if got, want := qa.Union(false, "SELECT 1", "  ", "SELECT 2 "), "SELECT 1 UNION SELECT 2"; got != want {
+		t.Fatalf("union mismatch: got %q, want %q", got, want)
+	}
+	if got, want := qa.Union(true, "SELECT 1", "SELECT 2"), "SELECT 1 UNION ALL SELECT 2"; got != want {
+		t.Fatalf("union all mismatch: got %q, want %q", got, want)
+	}
+	if got := qa.Union(true); got != "" {
+		t.Fatalf("expected empty union, got %q", got)
+	}
+}
+
+func TestRendererUnionPlaceholderContinuity(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	sql, args, err := r.FromString(
+		`{{ union true (printf "SELECT id FROM a WHERE x IN %s" (bind .X)) (printf "SELECT id FROM b WHERE y = %s" (bind .Y)) }}`,
+		map[string]any{"X": []int{1, 2}, "Y": 3},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT id FROM a WHERE x IN ($1, $2) UNION ALL SELECT id FROM b WHERE y = $3`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{1, 2, 3}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsDistinctOn(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	got, err := qa.DistinctOn("user_id", "t.day")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `DISTINCT ON ("user_id", "t"."day")`; got != want {
+		t.Fatalf("distinct on mismatch: got %q, want %q", got, want)
+	}
+
+	if _, err := qa.DistinctOn(); err == nil {
+		t.Fatal("expected error for no columns")
+	}
+	if _, err := qa.DistinctOn("bad col"); err == nil {
+		t.Fatal("expected error for invalid column")
+	}
+
+	for _, d := range []Dialect{DialectMySQL, DialectSQLite, DialectSQLServer, DialectOracle, DialectSnowflake} {
+		if _, err := NewQueryArgs(d).DistinctOn("user_id"); err == nil {
+			t.Fatalf("expected error for dialect %q", d)
+		} else if !strings.Contains(err.Error(), "ROW_NUMBER") {
+			t.Fatalf("error should point to the window rewrite: %v", err)
+		}
+	}
+}
+
+func TestRendererDistinctOn(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	sql, _, err := r.FromString(`SELECT {{ distinctOn "user_id" }} * FROM events ORDER BY user_id, at DESC`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT DISTINCT ON ("user_id") * FROM events ORDER BY user_id, at DESC`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+
+	if _, _, err := r.FromStringWithDialect(`SELECT {{ distinctOn "user_id" }} *`, nil, DialectMySQL); err == nil {
+		t.Fatal("expected error for mysql")
+	}
+}
+
+func TestQueryArgsWhere(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		or    bool
+		conds []any
+		want  string
+	}{
+		{"none", false, nil, ""},
+		{"all blank", false, []any{"", "  \n ", nil, false, 0}, ""},
+		{"single", false, []any{"", " a = 1 "}, "WHERE a = 1"},
+		{"and", false, []any{"a = 1", "", "b = 2 OR c = 3"}, "WHERE (a = 1) AND (b = 2 OR c = 3)"},
+		{"or", true, []any{"a = 1", "b = 2"}, "WHERE (a = 1) OR (b = 2)"},
+		{"or single", true, []any{"", "a = 1"}, "WHERE a = 1"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			qa := NewQueryArgs(DialectPostgres)
+			where := qa.Where
+			if tt.or {
+				where = qa.OrWhere
+			}
+			got, err := where(tt.conds...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("where mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewQueryArgs(DialectPostgres).Where("a = 1", 42); err == nil {
+		t.Fatal("expected error for non-string condition")
+	}
+}
+
+func TestRendererWhereOptionalFilters(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM users {{ where
+		(and .Status (printf "status = %s" (bind .Status)))
+		(and .Org (printf "org_id = %s" (bind .Org))) }}`
+
+	r := NewRenderer(DialectPostgres)
+
+	sql, args, err := r.FromString(tmpl, map[string]any{"Status": "", "Org": 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM users WHERE org_id = $1`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{4}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	sql, args, err = r.FromString(tmpl, map[string]any{"Status": "", "Org": 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM users `; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if len(args) != 0 {
+		t.Fatalf("expected no args, got %v", args)
+	}
+}
+
+func TestQueryArgsWith(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectMySQL)
+	got, err := qa.With(NewCTE("a", " SELECT 1 "), NewCTE("b", "SELECT 2", "x"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "WITH `a` AS (SELECT 1), `b` (`x`) AS (SELECT 2)"; got != want {
+		t.Fatalf("with mismatch: got %q, want %q", got, want)
+	}
+
+	if got, err := qa.With(); err != nil || got != "" {
+		t.Fatalf("expected empty output for no parts, got %q, %v", got, err)
+	}
+	if _, err := qa.With(NewCTE("a", "  ")); err == nil {
+		t.Fatal("expected error for empty CTE body")
+	}
+	if _, err := qa.With(NewCTE("a;drop", "SELECT 1")); err == nil {
+		t.Fatal("expected error for invalid CTE name")
+	}
+}
+
+func TestRendererWithCTEPlaceholderOrder(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `{{ $recent := printf "SELECT user_id FROM orders WHERE created_at > %s" (bind .Since) -}}
+{{ $totals := printf "SELECT user_id, SUM(amount) FROM orders WHERE status IN %s GROUP BY user_id" (bind .Statuses) -}}
+{{ withCTE (cte "recent" $recent) (cte "totals" $totals "user_id" "total") }} SELECT * FROM totals WHERE total > {{ bind .Min }}`
+
+	r := NewRenderer(DialectPostgres)
+	sql, args, err := r.FromString(tmpl, map[string]any{"Since": "2024-01-01", "Statuses": []string{"paid", "sent"}, "Min": 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `WITH "recent" AS (SELECT user_id FROM orders WHERE created_at > $1), ` +
+		`"totals" ("user_id", "total") AS (SELECT user_id, SUM(amount) FROM orders WHERE status IN ($2, $3) GROUP BY user_id) ` +
+		`SELECT * FROM totals WHERE total > $4`
+	if sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{"2024-01-01", "paid", "sent", 100}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsWithRecursive(t *testing.T) {
+	t.Parallel()
+
+	const body = "SELECT 1 UNION ALL SELECT n + 1 FROM t WHERE n < 5"
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectPostgres, `WITH RECURSIVE "t" ("n") AS (` + body + `)`},
+		{DialectMySQL, "WITH RECURSIVE `t` (`n`) AS (" + body + ")"},
+		{DialectSQLite, `WITH RECURSIVE "t" ("n") AS (` + body + `)`},
+		{DialectSnowflake, `WITH RECURSIVE "t" ("n") AS (` + body + `)`},
+		{DialectSQLServer, `WITH [t] ([n]) AS (` + body + `)`},
+		{DialectOracle, `WITH "t" ("n") AS (` + body + `)`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewQueryArgs(tt.dialect).WithRecursive(NewCTE("t", body, "n"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("with mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := NewQueryArgs(DialectPostgres).WithRecursive(); err != nil || got != "" {
+		t.Fatalf("expected empty output for no parts, got %q, %v", got, err)
+	}
+}
+
+func TestRendererWithRecursiveCTE(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `{{ withRecursiveCTE (cte "tree" (printf "SELECT id, parent_id FROM nodes WHERE id = %s UNION ALL SELECT n.id, n.parent_id FROM nodes n JOIN tree ON n.parent_id = tree.id" (bind .Root))) }} SELECT id FROM tree`
+
+	sql, args, err := NewRenderer(DialectSQLite).FromString(tmpl, map[string]any{"Root": 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `WITH RECURSIVE "tree" AS (SELECT id, parent_id FROM nodes WHERE id = ? UNION ALL SELECT n.id, n.parent_id FROM nodes n JOIN tree ON n.parent_id = tree.id) SELECT id FROM tree`
+	if sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{7}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsReturning(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+		wantErr bool
+	}{
+		{DialectPostgres, `RETURNING "id", "created_at"`, false},
+		{DialectSQLite, `RETURNING "id", "created_at"`, false},
+		{DialectOracle, `RETURNING "id", "created_at"`, false},
+		{DialectSQLServer, `OUTPUT INSERTED.[id], INSERTED.[created_at]`, false},
+		{DialectMySQL, "", true},
+		{DialectSnowflake, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewQueryArgs(tt.dialect).Returning("id", "created_at")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("returning mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewQueryArgs(DialectPostgres).Returning(); err == nil {
+		t.Fatal("expected error for no columns")
+	}
+}
+
+func TestRendererReturningSQLServer(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectSQLServer)
+	sql, _, err := r.FromString(`INSERT INTO users (name) {{ returning "id" }} VALUES ({{ bind .Name }})`, map[string]any{"Name": "a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `INSERT INTO users (name) OUTPUT INSERTED.[id] VALUES (@p1)`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestQueryArgsPaginate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		legacy   bool
+		order    string
+		want     string
+		wantArgs []any
+	}{
+		{"postgres", DialectPostgres, false, `"id"`, `SELECT id FROM t ORDER BY "id" LIMIT $1 OFFSET $2`, []any{10, 20}},
+		{"mysql unordered", DialectMySQL, false, "", `SELECT id FROM t LIMIT ? OFFSET ?`, []any{10, 20}},
+		{"sqlserver unordered", DialectSQLServer, false, "", `SELECT id FROM t ORDER BY (SELECT NULL) OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY`, []any{20, 10}},
+		{"oracle", DialectOracle, false, `"id"`, `SELECT id FROM t ORDER BY "id" OFFSET :1 ROWS FETCH NEXT :2 ROWS ONLY`, []any{20, 10}},
+		{
+			"legacy oracle", DialectOracle, true, `"id"`,
+			`SELECT * FROM (SELECT q.*, ROW_NUMBER() OVER (ORDER BY "id") rn FROM (SELECT id FROM t) q) WHERE rn BETWEEN :1 AND :2 ORDER BY rn`,
+			[]any{21, 30},
+		},
+		{"legacy flag ignored elsewhere", DialectPostgres, true, "", `SELECT id FROM t LIMIT $1 OFFSET $2`, []any{10, 20}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(tt.dialect)
+			qa.legacyOracle = tt.legacy
+			got, err := qa.Paginate("SELECT id FROM t", tt.order, 10, 20)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("sql mismatch: got %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestRendererLegacyOracle(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectOracle).SetLegacyOracle(true)
+
+	const tmpl = `{{ paginate (printf "SELECT id FROM users WHERE org = %s" (bind .Org)) "id" .Limit .Offset }}`
+	sql, args, err := r.FromString(tmpl, map[string]any{"Org": 7, "Limit": 25, "Offset": 50})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `SELECT * FROM (SELECT q.*, ROW_NUMBER() OVER (ORDER BY id) rn FROM (SELECT id FROM users WHERE org = :1) q) WHERE rn BETWEEN :2 AND :3 ORDER BY rn`
+	if sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	// Rows 51 through 75 are the third page of 25.
+	if want := []any{7, 51, 75}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	if _, _, err := r.FromString(`SELECT id FROM users {{ limit 10 false }}`, nil); err == nil || !strings.Contains(err.Error(), "paginate") {
+		t.Fatalf("expected limit to point to paginate, got %v", err)
+	}
+	if _, _, err := r.FromString(`{{ paginate "SELECT 1 FROM dual" "" 0 0 }}`, nil); err == nil {
+		t.Fatal("expected error for zero limit")
+	}
+}
+
+func TestQueryArgsBoolCombinators(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	tests := []struct {
+		name string
+		fn   func() (string, error)
+		want string
+	}{
+		{"and", func() (string, error) { return qa.And("x", "y") }, "(x AND y)"},
+		{"or", func() (string, error) { return qa.Or("x", "y") }, "(x OR y)"},
+		{"not", func() (string, error) { return qa.Not("x") }, "(NOT (x))"},
+		{"drops blanks", func() (string, error) { return qa.And("", "a = 1", nil, "  ", false) }, "a = 1"},
+		{"single grouped", func() (string, error) { return qa.Or("", "a = 1 AND b = 2") }, "(a = 1 AND b = 2)"},
+		{"all blank", func() (string, error) { return qa.Or("", nil) }, ""},
+		{"not blank", func() (string, error) { return qa.Not("") }, ""},
+		{"groups nested or", func() (string, error) { return qa.And("a = 1 OR b = 2", "c = 3") }, "((a = 1 OR b = 2) AND c = 3)"},
+		{"keeps grouped operand", func() (string, error) { return qa.And("(a = 1 OR b = 2)", "c = 3") }, "((a = 1 OR b = 2) AND c = 3)"},
+		{"ignores literals", func() (string, error) { return qa.Or("name = 'x and y'", `"or" = 1`) }, `(name = 'x and y' OR "or" = 1)`},
+		{"ignores word parts", func() (string, error) { return qa.Or("orders > 0", "brand = 1") }, "(orders > 0 OR brand = 1)"},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.fn()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s mismatch: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := qa.And("a = 1", 42); err == nil {
+		t.Fatal("expected error for non-string predicate")
+	}
+}
+
+func TestRendererBoolCombinators(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM users {{ where (sqlNot (sqlAnd
+		(and .Status (printf "status = %s" (bind .Status)))
+		(sqlOr "deleted" (and .Org (printf "org_id = %s" (bind .Org)))))) }}`
+
+	r := NewRenderer(DialectPostgres)
+	sql, args, err := r.FromString(tmpl, map[string]any{"Status": "active", "Org": 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM users WHERE (NOT ((status = $1 AND (deleted OR org_id = $2))))`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{"active", 4}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	sql, _, err = r.FromString(tmpl, map[string]any{"Status": "", "Org": 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM users WHERE (NOT (deleted))`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestQueryArgsColumns(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	if got, want := qa.Columns("id", "u.name"), `"id", "u"."name"`; got != want {
+		t.Fatalf("columns mismatch: got %q, want %q", got, want)
+	}
+	if got, want := qa.IdentifierList([]string{"org_id", "created_at"}), `"org_id", "created_at"`; got != want {
+		t.Fatalf("identifier list mismatch: got %q, want %q", got, want)
+	}
+	if got := qa.IdentifierList(nil); got != "" {
+		t.Fatalf("expected empty list, got %q", got)
+	}
+	if got, want := qa.JoinIdentifiers(" || ", `"First"`, "last"), `"First" || "last"`; got != want {
+		t.Fatalf("csv mismatch: got %q, want %q", got, want)
+	}
+	if len(qa.args) != 0 {
+		t.Fatalf("expected no args, got %v", qa.args)
+	}
+}
+
+func TestRendererColumnsAndCSV(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	sql, _, err := r.FromString(
+		`SELECT {{ identifierList .Group }}, COUNT(*) FROM t GROUP BY {{ csv ", " (identifier "a") "b" }}`,
+		map[string]any{"Group": []string{"a", "b"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT `a`, `b`, COUNT(*) FROM t GROUP BY `a`, `b`"; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+
+	for _, tmpl := range []string{
+		`{{ columns "id" "name; DROP TABLE t" }}`,
+		`{{ csv ", " "id DESC" }}`,
+		`{{ identifierList .Bad }}`,
+	} {
+		if _, _, err := r.FromString(tmpl, map[string]any{"Bad": []string{"a b"}}); err == nil {
+			t.Fatalf("expected error for %s", tmpl)
+		}
+	}
+}
+
+func TestQueryArgsIdentifierWhitelist(t *testing.T) {
+	t.Parallel()
+
+	allowed := map[string]bool{"id": true, "name": true, "email": true, "secret": false}
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    string
+		wantErr string
+	}{
+		{"allowed", []string{"name", "id"}, `"name", "id"`, ""},
+		{"empty", nil, "", ""},
+		{"disallowed column", []string{"id", "password"}, "", `"password" is not allowed`},
+		{"explicitly false", []string{"secret"}, "", `"secret" is not allowed`},
+		{"case sensitive", []string{"ID"}, "", `"ID" is not allowed`},
+		{"injection", []string{"id; DROP TABLE users"}, "", "is not allowed"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewQueryArgs(DialectPostgres).IdentifierWhitelist(tt.names, allowed)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("list mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewQueryArgs(DialectPostgres).IdentifierWhitelist([]string{"a b"}, map[string]bool{"a b": true}); err == nil {
+		t.Fatal("expected error for an allowed but invalid identifier")
+	}
+}
+
+func TestQueryArgsAgg(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fn       string
+		column   string
+		distinct bool
+		want     string
+	}{
+		{"COUNT", "*", false, "COUNT(*)"},
+		{"count", "user_id", true, `COUNT(DISTINCT "user_id")`},
+		{"Sum", "o.amount", false, `SUM("o"."amount")`},
+		{"AVG", "score", false, `AVG("score")`},
+		{"min", "created_at", false, `MIN("created_at")`},
+		{"MAX", "total", true, `MAX(DISTINCT "total")`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			if got := NewQueryArgs(DialectPostgres).Agg(tt.fn, tt.column, tt.distinct); got != tt.want {
+				t.Fatalf("aggregate mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererAggErrors(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	sql, _, err := r.FromString(`SELECT {{ agg "count" "id" true }} FROM t`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT COUNT(DISTINCT `id`) FROM t"; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+
+	for _, tmpl := range []string{
+		`{{ agg "pg_sleep" "id" false }}`,
+		`{{ agg "COUNT(*)); DROP TABLE t; --" "id" false }}`,
+		`{{ agg "SUM" "*" false }}`,
+		`{{ agg "COUNT" "*" true }}`,
+		`{{ agg "SUM" "a + b" false }}`,
+	} {
+		if _, _, err := r.FromString(tmpl, nil); err == nil {
+			t.Fatalf("expected error for %s", tmpl)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package sqlrender
+
+import (
+	"strconv"
+	"sync"
+)
+
+// DialectSpec teaches sqlrender how to render placeholders and quote
+// identifiers for a dialect that is not built in. Either function may be nil,
+// in which case the built-in default (`?` placeholders, backtick quoting) is
+// used for that part.
+type DialectSpec struct {
+	// Placeholder returns the placeholder for the n-th (1-based) argument.
+	Placeholder func(n int) string
+	// Quote quotes a single, already validated identifier part.
+	Quote func(id string) string
+}
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = map[Dialect]DialectSpec{}
+)
+
+// RegisterDialect makes spec available under name for every QueryArgs and
+// Renderer. Registered dialects are consulted before the built-in rules, so
+// registering a built-in name overrides it. Registering an empty name panics.
+func RegisterDialect(name Dialect, spec DialectSpec) {
+	if name == "" {
+		panic("sqlrender: RegisterDialect called with empty name")
+	}
+
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[name] = spec
+}
+
+func lookupDialect(name Dialect) (DialectSpec, bool) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	spec, ok := dialects[name]
+	return spec, ok
+}
+
+// QuoteStyle selects how identifiers are quoted.
+type QuoteStyle int
+
+const (
+	// QuoteDefault uses the dialect's built-in (or registered) quoting.
+	QuoteDefault QuoteStyle = iota
+	// QuoteDouble quotes with "double quotes", the SQL standard.
+	QuoteDouble
+	// QuoteBacktick quotes with `backticks`.
+	QuoteBacktick
+	// QuoteBracket quotes with [brackets].
+	QuoteBracket
+)
+
+// quote wraps id according to the style; QuoteDefault returns false.
+func (s QuoteStyle) quote(id string) (string, bool) {
+	switch s {
+	case QuoteDouble:
+		return `"` + id + `"`, true
+	case QuoteBacktick:
+		return "`" + id + "`", true
+	case QuoteBracket:
+		return `[` + id + `]`, true
+	default:
+		return "", false
+	}
+}
+
+// SetQuoteStyle overrides identifier quoting for one dialect on this
+// renderer, e.g. backticks for SQLite or double quotes for MySQL running with
+// ANSI_QUOTES. The override takes precedence over RegisterDialect; passing
+// QuoteDefault removes it.
+func (r *Renderer) SetQuoteStyle(dialect Dialect, style QuoteStyle) *Renderer {
+	if style == QuoteDefault {
+		delete(r.quoteStyles, dialect)
+		return r
+	}
+	if r.quoteStyles == nil {
+		r.quoteStyles = make(map[Dialect]QuoteStyle)
+	}
+	r.quoteStyles[dialect] = style
+	return r
+}
+
+// PlaceholderStyle selects how bound-argument placeholders are written,
+// independently of the dialect.
+type PlaceholderStyle int
+
+const (
+	// PlaceholderDefault uses the dialect's built-in (or registered) style.
+	PlaceholderDefault PlaceholderStyle = iota
+	// PlaceholderQuestion writes ? for every argument.
+	PlaceholderQuestion
+	// PlaceholderDollar writes $1, $2, ...
+	PlaceholderDollar
+	// PlaceholderColon writes :1, :2, ...
+	PlaceholderColon
+	// PlaceholderAtP writes @p1, @p2, ...
+	PlaceholderAtP
+)
+
+// placeholder renders the n-th placeholder in the style; PlaceholderDefault
+// returns false.
+func (s PlaceholderStyle) placeholder(n int) (string, bool) {
+	switch s {
+	case PlaceholderQuestion:
+		return "?", true
+	case PlaceholderDollar:
+		return "$" + strconv.Itoa(n), true
+	case PlaceholderColon:
+		return ":" + strconv.Itoa(n), true
+	case PlaceholderAtP:
+		return "@p" + strconv.Itoa(n), true
+	default:
+		return "", false
+	}
+}
+
+// SetPlaceholderStyle overrides the placeholder format for every dialect
+// rendered by this renderer while leaving identifier quoting and other
+// dialect behaviour alone, e.g. numbered $N placeholders for MySQL output fed
+// through a proxy that rewrites them. The override takes precedence over
+// RegisterDialect; PlaceholderDefault removes it.
+func (r *Renderer) SetPlaceholderStyle(style PlaceholderStyle) *Renderer {
+	r.placeholderStyle = style
+	return r
+}
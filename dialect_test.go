@@ -0,0 +1,131 @@
+package sqlrender
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestRegisterDialect(t *testing.T) {
+	t.Parallel()
+
+	const clickhouse Dialect = "test_clickhouse"
+	RegisterDialect(clickhouse, DialectSpec{
+		Placeholder: func(n int) string { return "{p" + strconv.Itoa(n) + ":String}" },
+		Quote:       func(id string) string { return "<" + id + ">" },
+	})
+
+	r := NewRenderer(DialectPostgres)
+	sql, args, err := r.FromStringWithDialect(
+		`SELECT * FROM {{ identifier "db.events" }} WHERE id IN {{ bind .IDs }}`,
+		map[string]any{"IDs": []int{1, 2}},
+		clickhouse,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM <db>.<events> WHERE id IN ({p1:String}, {p2:String})`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{1, 2}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestRegisterDialectPartialSpec(t *testing.T) {
+	t.Parallel()
+
+	const partial Dialect = "test_partial"
+	RegisterDialect(partial, DialectSpec{
+		Placeholder: func(n int) string { return "%" + strconv.Itoa(n) },
+	})
+
+	qa := NewQueryArgs(partial)
+	if got := qa.Bind(1); got != "%1" {
+		t.Fatalf("placeholder mismatch: got %q, want %q", got, "%1")
+	}
+	if got := qa.Identifier("users"); got != "`users`" {
+		t.Fatalf("identifier mismatch: got %q, want %q", got, "`users`")
+	}
+}
+
+func TestRegisterDialectEmptyName(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic for empty dialect name")
+		}
+	}()
+	RegisterDialect("", DialectSpec{})
+}
+
+func TestRendererSetQuoteStyle(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectSQLite).
+		SetQuoteStyle(DialectSQLite, QuoteBacktick).
+		SetQuoteStyle(DialectMySQL, QuoteDouble)
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectSQLite, "SELECT `main`.`users`"},
+		{DialectMySQL, `SELECT "main"."users"`},
+		{DialectPostgres, `SELECT "main"."users"`},
+	}
+	for _, tt := range tests {
+		sql, _, err := r.FromStringWithDialect(`SELECT {{ identifier "main.users" }}`, nil, tt.dialect)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sql != tt.want {
+			t.Fatalf("%s quoting mismatch: got %q, want %q", tt.dialect, sql, tt.want)
+		}
+	}
+
+	sql, _, err := r.SetQuoteStyle(DialectSQLite, QuoteDefault).FromString(`SELECT {{ identifier "users" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT "users"`; sql != want {
+		t.Fatalf("default quoting mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestRendererSetPlaceholderStyle(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL).SetPlaceholderStyle(PlaceholderDollar).SetStrictArgs(true)
+	sql, args, err := r.FromString("SELECT * FROM {{ identifier \"t\" }} WHERE a = {{ bind .A }} AND b IN {{ bind .B }}",
+		map[string]any{"A": 1, "B": []int{2, 3}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT * FROM `t` WHERE a = $1 AND b IN ($2, $3)"; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if len(args) != 3 {
+		t.Fatalf("expected 3 args, got %v", args)
+	}
+
+	styles := []struct {
+		style PlaceholderStyle
+		want  string
+	}{
+		{PlaceholderQuestion, "?, ?"},
+		{PlaceholderColon, ":1, :2"},
+		{PlaceholderAtP, "@p1, @p2"},
+		{PlaceholderDefault, "$1, $2"},
+	}
+	for _, tt := range styles {
+		sql, _, err := r.SetPlaceholderStyle(tt.style).FromStringWithDialect(`{{ bind .A }}, {{ bind .B }}`, map[string]any{"A": 1, "B": 2}, DialectPostgres)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sql != tt.want {
+			t.Fatalf("style %d mismatch: got %q, want %q", tt.style, sql, tt.want)
+		}
+	}
+}
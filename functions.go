@@ -0,0 +1,14 @@
+package sqlrender
+
+// CurrentDate returns the dialect's expression for today's date without a
+// time component.
+func (qa *QueryArgs) CurrentDate() string {
+	switch qa.dialect {
+	case DialectSQLServer:
+		return "CAST(GETDATE() AS date)"
+	case DialectOracle:
+		return "TRUNC(SYSDATE)"
+	default:
+		return "CURRENT_DATE" // Postgres, MySQL, SQLite, Snowflake
+	}
+}
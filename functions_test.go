@@ -0,0 +1,41 @@
+package sqlrender
+
+import "testing"
+
+func TestQueryArgsCurrentDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectPostgres, "CURRENT_DATE"},
+		{DialectMySQL, "CURRENT_DATE"},
+		{DialectSQLite, "CURRENT_DATE"},
+		{DialectSnowflake, "CURRENT_DATE"},
+		{DialectSQLServer, "CAST(GETDATE() AS date)"},
+		{DialectOracle, "TRUNC(SYSDATE)"},
+	}
+
+	for _, tt := range tests {
+		if got := NewQueryArgs(tt.dialect).CurrentDate(); got != tt.want {
+			t.Fatalf("%s: got %q, want %q", tt.dialect, got, tt.want)
+		}
+	}
+}
+
+func TestRendererCurrentDate(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectSQLServer)
+	sql, args, err := r.FromString(`WHERE due_on < {{ currentDate }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `WHERE due_on < CAST(GETDATE() AS date)`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if len(args) != 0 {
+		t.Fatalf("expected no args, got %v", args)
+	}
+}
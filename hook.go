@@ -0,0 +1,44 @@
+package sqlrender
+
+import "time"
+
+// RenderEvent describes one finished render and is passed to the hook
+// installed with SetRenderHook.
+type RenderEvent struct {
+	// Template is the template file name, or "" for string renders.
+	Template string
+	// SQL is the rendered statement. It is empty when rendering failed and
+	// for FromStringTo, which streams the output instead of keeping it.
+	SQL string
+	// ArgCount is the number of bound arguments.
+	ArgCount int
+	Dialect  Dialect
+	// Duration covers template parsing and execution; loading a template
+	// file is not included.
+	Duration time.Duration
+	// Err is the render error, or nil on success.
+	Err error
+}
+
+// SetRenderHook installs fn to be called synchronously after every render,
+// successful or not, e.g. to log slow templates or record metrics centrally.
+// Passing nil removes the hook.
+func (r *Renderer) SetRenderHook(fn func(RenderEvent)) *Renderer {
+	r.hook = fn
+	return r
+}
+
+// observe reports a finished render of qa to the hook, if any.
+func (r *Renderer) observe(qa *QueryArgs, sql string, start time.Time, err error) {
+	if r.hook == nil {
+		return
+	}
+	r.hook(RenderEvent{
+		Template: qa.template,
+		SQL:      sql,
+		ArgCount: len(qa.args),
+		Dialect:  qa.dialect,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+}
@@ -0,0 +1,65 @@
+package sqlrender
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRendererRenderHook(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "q.sql"), []byte(`SELECT {{ bind .A }}, {{ bind .B }}`), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	var events []RenderEvent
+	r := NewRenderer(DialectPostgres).AddSearchPath(dir).SetRenderHook(func(ev RenderEvent) {
+		events = append(events, ev)
+	})
+	data := map[string]any{"A": 1, "B": 2}
+
+	if _, _, err := r.FromString(`SELECT {{ bind .A }}`, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := r.FromTemplateWithDialect("q.sql", data, DialectMySQL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := r.FromString(`{{ bind }}`, data); err == nil {
+		t.Fatal("expected render error")
+	}
+	if _, _, err := r.FromTemplate("missing.sql", data); err == nil {
+		t.Fatal("expected load error")
+	}
+	var buf bytes.Buffer
+	if _, err := r.FromStringTo(&buf, `SELECT 1`, nil, DialectSQLite); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 5 {
+		t.Fatalf("event count mismatch: got %d, want 5", len(events))
+	}
+
+	if ev := events[0]; ev.Template != "" || ev.SQL != "SELECT $1" || ev.ArgCount != 1 || ev.Dialect != DialectPostgres || ev.Err != nil {
+		t.Fatalf("string render event mismatch: %+v", ev)
+	}
+	if ev := events[1]; ev.Template != "q.sql" || ev.SQL != "SELECT ?, ?" || ev.ArgCount != 2 || ev.Dialect != DialectMySQL || ev.Err != nil {
+		t.Fatalf("template render event mismatch: %+v", ev)
+	}
+	if ev := events[2]; ev.Err == nil || ev.SQL != "" {
+		t.Fatalf("failed render event mismatch: %+v", ev)
+	}
+	if ev := events[3]; ev.Err == nil || ev.Template != "missing.sql" {
+		t.Fatalf("load failure event mismatch: %+v", ev)
+	}
+	if ev := events[4]; ev.Err != nil || ev.SQL != "" || ev.Dialect != DialectSQLite {
+		t.Fatalf("streamed render event mismatch: %+v", ev)
+	}
+	for i, ev := range events {
+		if ev.Duration < 0 {
+			t.Fatalf("event %d has negative duration %v", i, ev.Duration)
+		}
+	}
+}
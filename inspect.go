@@ -0,0 +1,80 @@
+package sqlrender
+
+import (
+	"context"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateFields parses s and returns the sorted, de-duplicated top-level
+// data fields it references, such as "IDs" for `.IDs` and "User" for
+// `.User.Name`, so callers can check their data before rendering. Inside
+// range and with blocks dot is rebound, so only `$.Field` references count
+// there. Templates invoked with {{ template }} are not followed.
+func (r *Renderer) TemplateFields(s string) ([]string, error) {
+	funcMap := r.funcMap(context.Background(), r.newQueryArgs(r.defaultDialect))
+	tmpl, err := template.New("sql").Funcs(funcMap).Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool)
+	if tmpl.Tree != nil {
+		collectFields(tmpl.Tree.Root, true, fields)
+	}
+	return sortedKeys(fields), nil
+}
+
+// collectFields records the top-level fields referenced under node. topDot
+// reports whether dot still refers to the template's data.
+func collectFields(node parse.Node, topDot bool, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(child, topDot, fields)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, topDot, fields)
+	case *parse.IfNode:
+		collectBranch(&n.BranchNode, topDot, topDot, fields)
+	case *parse.RangeNode:
+		collectBranch(&n.BranchNode, topDot, false, fields)
+	case *parse.WithNode:
+		collectBranch(&n.BranchNode, topDot, false, fields)
+	case *parse.TemplateNode:
+		collectFields(n.Pipe, topDot, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectFields(cmd, topDot, fields)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectFields(arg, topDot, fields)
+		}
+	case *parse.ChainNode:
+		collectFields(n.Node, topDot, fields)
+	case *parse.FieldNode:
+		if topDot && len(n.Ident) > 0 {
+			fields[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			fields[n.Ident[1]] = true
+		}
+	}
+}
+
+// collectBranch walks an if/range/with node. The pipeline is evaluated with
+// the outer dot; the body sees bodyDot, while the else branch keeps the outer
+// dot.
+func collectBranch(n *parse.BranchNode, topDot, bodyDot bool, fields map[string]bool) {
+	collectFields(n.Pipe, topDot, fields)
+	collectFields(n.List, bodyDot, fields)
+	collectFields(n.ElseList, topDot, fields)
+}
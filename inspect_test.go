@@ -0,0 +1,39 @@
+package sqlrender
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRendererTemplateFields(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM {{ table .Table }}
+WHERE id IN {{ bind .IDs }}
+{{- if .User.Active }} AND owner = {{ bind .User.ID }}{{ end }}
+{{- range .Filters }} AND {{ identifier .Column }} = {{ bind $.Default }}{{ end }}
+{{- with .Org }} AND org = {{ bind .ID }}{{ else }} AND org IS NULL {{ .Fallback }}{{ end }}
+{{- $limit := .Limit }} LIMIT {{ bind $limit }}`
+
+	r := NewRenderer(DialectPostgres)
+	got, err := r.TemplateFields(tmpl)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"Default", "Fallback", "Filters", "IDs", "Limit", "Org", "Table", "User"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("fields mismatch: got %v, want %v", got, want)
+	}
+
+	if _, err := r.TemplateFields(`{{ unknownFunc .X }}`); err == nil {
+		t.Fatal("expected parse error for unknown function")
+	}
+
+	got, err = r.TemplateFields(`SELECT 1`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no fields, got %v", got)
+	}
+}
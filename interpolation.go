@@ -0,0 +1,378 @@
+package sqlrender
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"text/template"
+	"text/template/parse"
+)
+
+// boundFuncs are the template functions whose output never carries template
+// data verbatim: they bind, quote or validate every argument, or compute a
+// value such as a comparison from them.
+var boundFuncs = map[string]bool{
+	// text/template builtins returning a bool or a length.
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true,
+	"not": true, "len": true,
+
+	"bind":                true,
+	"bindList":            true,
+	"bindNamedPositional": true,
+	"bindNamed":           true,
+	"bindIf":              true,
+	"bindOrDefault":       true,
+	"bindVarchar":         true,
+	"bindCast":            true,
+	"bindCastSlice":       true,
+	"bindJSON":            true,
+	"stringLit":           true,
+	"identifier":          true,
+	"tableIdentifier":     true,
+	"table":               true,
+	"columnIdentifier":    true,
+	"columns":             true,
+	"identifierList":      true,
+	"identifierWhitelist": true,
+	"agg":                 true,
+	"orderBy":             true,
+	"explain":             true,
+	"onConflict":          true,
+	"upsertWhereChanged":  true,
+	"returning":           true,
+	"top":                 true,
+	"limit":               true,
+	"set":                 true,
+	"setChanged":          true,
+	"insertStruct":        true,
+	"insert":              true,
+	"valuesAs":            true,
+	"valuesFields":        true,
+	"distinctOn":          true,
+	"between":             true,
+	"in":                  true,
+	"notIn":               true,
+	"like":                true,
+	"inChunked":           true,
+	"boolEq":              true,
+	"boolLit":             true,
+	"eqNullable":          true,
+	"eqAll":               true,
+	"neNullable":          true,
+	"currentDate":         true,
+	"dialect":             true,
+}
+
+// argSpan is the range of arguments, by index, that a fragment helper
+// splices into its output unchanged. last < 0 runs to the final argument.
+type argSpan struct {
+	first, last int
+}
+
+// fragmentFuncs are the helpers that compose already-rendered SQL. Their
+// output is safe only when every spliced argument is itself a helper's
+// output or a literal.
+var fragmentFuncs = map[string]argSpan{
+	"where":            {0, -1},
+	"orWhere":          {0, -1},
+	"sqlAnd":           {0, -1},
+	"sqlOr":            {0, -1},
+	"sqlNot":           {0, -1},
+	"union":            {1, -1},
+	"paginate":         {0, 1},
+	"cte":              {1, 1},
+	"withCTE":          {0, -1},
+	"withRecursiveCTE": {0, -1},
+	"csv":              {0, 0},
+}
+
+// SetStrictInterpolation makes every parse reject templates that write
+// template data straight into the SQL, such as {{ .Name }},
+// {{ printf "%s" .Name }} or {{ where .Filter }}, instead of passing it
+// through a helper like bind or identifier. Each action that produces output
+// must end in a helper that binds, quotes or validates its arguments.
+// Helpers that splice SQL fragments (where, sqlAnd, union, paginate, withCTE,
+// ...) are accepted only when those fragments come from helpers or literals
+// themselves. A dot or variable holding a helper's result, fields of such a
+// result ({{ $ins.Columns }} after insert), and functions registered with
+// AddFunc or FromStringWithFuncs are accepted too; builtins
+// such as print, printf, index, and and or, and the default and coalesceVal
+// helpers, are not. The check is static, so a violation fails the render, or
+// PreloadAll, before anything is executed. It is off by default.
+func (r *Renderer) SetStrictInterpolation(on bool) *Renderer {
+	r.strictInterp = on
+	return r
+}
+
+// interpChecker walks parse trees for strict interpolation. custom reports
+// whether a function was registered by the caller and is trusted as a
+// helper. exits collects the variable states at break and continue actions
+// of the innermost range being checked.
+type interpChecker struct {
+	custom func(name string) bool
+	exits  *[]varScopes
+}
+
+// varScopes records whether each template variable holds a helper's result,
+// one map per nested if/range/with, innermost last, mirroring text/template's
+// scoping: `:=` declares in the innermost scope and `=` assigns wherever the
+// variable was declared.
+type varScopes []map[string]bool
+
+func (s varScopes) clone() varScopes {
+	out := make(varScopes, len(s))
+	for i, m := range s {
+		out[i] = maps.Clone(m)
+	}
+	return out
+}
+
+func (s varScopes) lookup(name string) bool {
+	for i := len(s) - 1; i >= 0; i-- {
+		if safe, ok := s[i][name]; ok {
+			return safe
+		}
+	}
+	return false
+}
+
+func (s varScopes) assign(name string, safe bool) {
+	for i := len(s) - 1; i >= 0; i-- {
+		if _, ok := s[i][name]; ok {
+			s[i][name] = safe
+			return
+		}
+	}
+}
+
+// setVars declares or assigns the variables of pipe.
+func (s varScopes) setVars(pipe *parse.PipeNode, safe bool) {
+	for _, v := range pipe.Decl {
+		if pipe.IsAssign {
+			s.assign(v.Ident[0], safe)
+		} else {
+			s[len(s)-1][v.Ident[0]] = safe
+		}
+	}
+}
+
+// meet leaves a variable in s safe only if it is safe in other too, for
+// merging the states reached along different paths. other must have at
+// least as many scopes as s.
+func (s varScopes) meet(other varScopes) {
+	for i, m := range s {
+		for name, safe := range m {
+			m[name] = safe && other[i][name]
+		}
+	}
+}
+
+// update copies into s the states other holds for the variables of s.
+func (s varScopes) update(other varScopes) {
+	for i, m := range s {
+		for name := range m {
+			m[name] = other[i][name]
+		}
+	}
+}
+
+func (s varScopes) equal(other varScopes) bool {
+	for i, m := range s {
+		if !maps.Equal(m, other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkInterpolation returns an error for the first action in tmpl, or a
+// template it defines, that outputs template data without a helper.
+func checkInterpolation(tmpl *template.Template, custom func(name string) bool) error {
+	trees := make(map[string]*parse.Tree)
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			trees[t.Name()] = t.Tree
+		}
+	}
+	c := &interpChecker{custom: custom}
+	for _, name := range slices.Sorted(maps.Keys(trees)) {
+		tree := trees[name]
+		if node := c.unsafeOutput(tree.Root, false, varScopes{{}}); node != nil {
+			location, context := tree.ErrorContext(node)
+			return fmt.Errorf("sqlrender: %s: %s outputs template data without a helper such as bind", location, context)
+		}
+	}
+	return nil
+}
+
+// unsafeOutput returns the first action under node that writes template
+// data. dotSafe reports whether dot holds a helper's result; vars records
+// the same for variables and is updated by declarations and assignments.
+func (c *interpChecker) unsafeOutput(node parse.Node, dotSafe bool, vars varScopes) parse.Node {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if bad := c.unsafeOutput(child, dotSafe, vars); bad != nil {
+				return bad
+			}
+		}
+	case *parse.ActionNode:
+		safe := c.safePipe(n.Pipe, dotSafe, vars)
+		if len(n.Pipe.Decl) == 0 {
+			if !safe {
+				return n
+			}
+			return nil
+		}
+		vars.setVars(n.Pipe, safe)
+	case *parse.BreakNode, *parse.ContinueNode:
+		if c.exits != nil {
+			*c.exits = append(*c.exits, vars.clone())
+		}
+	case *parse.IfNode:
+		return c.unsafeIfOrWith(&n.BranchNode, dotSafe, dotSafe, c.safePipe(n.Pipe, dotSafe, vars), vars)
+	case *parse.WithNode:
+		pipeSafe := c.safePipe(n.Pipe, dotSafe, vars)
+		return c.unsafeIfOrWith(&n.BranchNode, dotSafe, pipeSafe, pipeSafe, vars)
+	case *parse.RangeNode:
+		return c.unsafeRange(n, dotSafe, vars)
+	}
+	return nil
+}
+
+// enter returns a copy of vars with a new innermost scope holding the
+// variables a branch's pipeline declares, or with its assignments applied.
+func enter(vars varScopes, pipe *parse.PipeNode, safe bool) varScopes {
+	inner := append(vars.clone(), map[string]bool{})
+	inner.setVars(pipe, safe)
+	return inner
+}
+
+// unsafeIfOrWith checks the body of an if or with with dot set to bodyDot
+// and the else branch, which may be empty, with the outer dot, then leaves
+// each variable of vars safe only if both paths do. Variables set by the
+// pipeline get pipeSafe: an if keeps the outer dot but its `$x := ...`
+// still holds the pipeline's value.
+func (c *interpChecker) unsafeIfOrWith(n *parse.BranchNode, dotSafe, bodyDot, pipeSafe bool, vars varScopes) parse.Node {
+	body := enter(vars, n.Pipe, pipeSafe)
+	if bad := c.unsafeOutput(n.List, bodyDot, body); bad != nil {
+		return bad
+	}
+	alt := enter(vars, n.Pipe, pipeSafe)
+	if bad := c.unsafeOutput(n.ElseList, dotSafe, alt); bad != nil {
+		return bad
+	}
+	body.meet(alt)
+	vars.update(body)
+	return nil
+}
+
+// unsafeRange checks a range body until the variable states at the top of
+// the loop stop changing, so a value assigned in one iteration is seen by
+// the next, then merges the states after the loop and its else branch.
+func (c *interpChecker) unsafeRange(n *parse.RangeNode, dotSafe bool, vars varScopes) parse.Node {
+	elemSafe := c.safePipe(n.Pipe, dotSafe, vars)
+	outer := c.exits
+	defer func() { c.exits = outer }()
+
+	head := vars.clone()
+	for {
+		var exits []varScopes
+		c.exits = &exits
+		body := enter(head, n.Pipe, elemSafe)
+		if bad := c.unsafeOutput(n.List, elemSafe, body); bad != nil {
+			return bad
+		}
+		next := head.clone()
+		next.meet(body)
+		for _, exit := range exits {
+			next.meet(exit)
+		}
+		if next.equal(head) {
+			break
+		}
+		head = next
+	}
+	c.exits = outer
+
+	alt := enter(vars, n.Pipe, elemSafe)
+	if bad := c.unsafeOutput(n.ElseList, dotSafe, alt); bad != nil {
+		return bad
+	}
+	vars.meet(head)
+	vars.meet(alt)
+	return nil
+}
+
+// safePipe reports whether a pipeline's value comes from a helper. Each
+// command after the first receives the previous command's value as its
+// final argument.
+func (c *interpChecker) safePipe(pipe *parse.PipeNode, dotSafe bool, vars varScopes) bool {
+	if pipe == nil || len(pipe.Cmds) == 0 {
+		return false
+	}
+	var piped *bool
+	for _, cmd := range pipe.Cmds {
+		safe := c.safeCmd(cmd, piped, dotSafe, vars)
+		piped = &safe
+	}
+	return *piped
+}
+
+// safeCmd reports whether a command's value comes from a helper. piped is
+// the safety of the value piped into it, or nil for the first command.
+func (c *interpChecker) safeCmd(cmd *parse.CommandNode, piped *bool, dotSafe bool, vars varScopes) bool {
+	fn, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		return piped == nil && len(cmd.Args) == 1 && c.safeArg(cmd.Args[0], dotSafe, vars)
+	}
+	if c.custom(fn.Ident) || boundFuncs[fn.Ident] {
+		return true
+	}
+	span, ok := fragmentFuncs[fn.Ident]
+	if !ok {
+		return false
+	}
+
+	args := cmd.Args[1:]
+	n := len(args)
+	if piped != nil {
+		n++
+	}
+	for i := span.first; i < n && (span.last < 0 || i <= span.last); i++ {
+		var safe bool
+		if i < len(args) {
+			safe = c.safeArg(args[i], dotSafe, vars)
+		} else {
+			safe = *piped
+		}
+		if !safe {
+			return false
+		}
+	}
+	return true
+}
+
+// safeArg reports whether an argument node evaluates to a helper's output or
+// a literal. Fields of a helper's result, such as the Columns and Values of
+// insert's InsertClause, are helper output too.
+func (c *interpChecker) safeArg(node parse.Node, dotSafe bool, vars varScopes) bool {
+	switch arg := node.(type) {
+	case *parse.StringNode, *parse.NumberNode, *parse.BoolNode, *parse.NilNode:
+		return true
+	case *parse.DotNode, *parse.FieldNode:
+		return dotSafe
+	case *parse.VariableNode:
+		return vars.lookup(arg.Ident[0])
+	case *parse.ChainNode:
+		return c.safeArg(arg.Node, dotSafe, vars)
+	case *parse.IdentifierNode:
+		return c.safeCmd(&parse.CommandNode{Args: []parse.Node{arg}}, nil, dotSafe, vars)
+	case *parse.PipeNode:
+		return c.safePipe(arg, dotSafe, vars)
+	}
+	return false
+}
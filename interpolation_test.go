@@ -0,0 +1,171 @@
+package sqlrender
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRendererStrictInterpolation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr string
+	}{
+		{"bind", `WHERE id = {{ bind .ID }}`, ""},
+		{"piped helper", `WHERE id = {{ .ID | bind }}`, ""},
+		{"parenthesized helper", `WHERE id = {{ (bind .ID) }}`, ""},
+		{"literal", `LIMIT {{ 10 }}`, ""},
+		{"custom func", `WHERE {{ tenant }}`, ""},
+		{"with helper result", `{{ with bindIf .On .ID }}AND id = {{ . }}{{ end }}`, ""},
+		{"variable helper result", `{{ $id := bind .ID }}{{ $id }} = {{ $id }}`, ""},
+		{"range over data", `{{ range .Cols }}{{ identifier . }}{{ end }}`, ""},
+		{"defined template", `{{ define "f" }}{{ bind .ID }}{{ end }}{{ template "f" . }}`, ""},
+		{"comparison only in if", `{{ if eq .Name "x" }}1{{ end }}`, ""},
+		{"field", `WHERE name = '{{ .Name }}'`, "{{.Name}}"},
+		{"printf", `WHERE name = {{ printf "%q" .Name }}`, `{{printf "%q" .Name}}`},
+		{"piped printf", `WHERE name = {{ bind .Name | printf "%s" }}`, "outputs template data"},
+		{"default", `LIMIT {{ default .Limit 10 }}`, "{{default .Limit 10}}"},
+		{"range dot", `{{ range .Names }}{{ . }}{{ end }}`, "{{.}}"},
+		{"with data", `{{ with .Name }}{{ . }}{{ end }}`, "{{.}}"},
+		{"else keeps outer dot", `{{ with bindIf .On .ID }}{{ . }}{{ else }}{{ . }}{{ end }}`, "{{.}}"},
+		{"variable data", `{{ $n := .Name }}{{ $n }}`, "{{$n}}"},
+		{"variable field", `{{ $.Name }}`, "{{$.Name}}"},
+		{"inside if", `{{ if .On }}{{ .Name }}{{ end }}`, "{{.Name}}"},
+		{"defined template body", `{{ define "f" }}{{ .Name }}{{ end }}`, "{{.Name}}"},
+		{"where data", `{{ where .X }}`, "{{where .X}}"},
+		{"where piped data", `{{ .X | where }}`, "{{.X | where}}"},
+		{"orWhere data", `{{ orWhere (bind .ID) .X }}`, "orWhere"},
+		{"sqlAnd data", `{{ sqlAnd .X }}`, "{{sqlAnd .X}}"},
+		{"sqlOr data", `{{ sqlOr (eqNullable "a" .ID) .X }}`, "sqlOr"},
+		{"sqlNot data", `{{ sqlNot .X }}`, "{{sqlNot .X}}"},
+		{"union data", `{{ union false "SELECT 1" .X }}`, "union"},
+		{"paginate data", `{{ paginate .X "" 1 0 }}`, "paginate"},
+		{"paginate order data", `{{ paginate "SELECT 1" .X 1 0 }}`, "paginate"},
+		{"cte data", `{{ withCTE (cte "a" .X) }}`, "withCTE"},
+		{"recursive cte data", `{{ withRecursiveCTE (cte "a" .X) }}`, "withRecursiveCTE"},
+		{"cte printed", `{{ cte "a" .X }}`, "cte"},
+		{"csv separator data", `{{ csv .X "a" "b" }}`, "csv"},
+		{"index", `{{ index .Names 0 }}`, "index"},
+		{"and", `{{ and .On .Name }}`, "and"},
+		{"reassigned in if", `{{ $x := bind 1 }}{{ if true }}{{ $x = .Name }}{{ end }}{{ $x }}`, "{{$x}}"},
+		{"reassigned in range", `{{ $x := bind 1 }}{{ range $i, $e := .Names }}{{ $x = $e }}{{ end }}{{ $x }}`, "{{$x}}"},
+		{"reassigned in else", `{{ $x := bind 1 }}{{ if .On }}{{ $x = bind 2 }}{{ else }}{{ $x = .Name }}{{ end }}{{ $x }}`, "{{$x}}"},
+		{"reassigned before break", `{{ $x := bind 1 }}{{ range .Names }}{{ $x = . }}{{ break }}{{ $x = bind 2 }}{{ end }}{{ $x }}`, "{{$x}}"},
+		{"used in next iteration", `{{ $x := bind 1 }}{{ range .Names }}{{ $x }}{{ $x = . }}{{ end }}`, "{{$x}}"},
+		{"chained through loop", `{{ $x := bind 1 }}{{ $y := bind 2 }}{{ range .Names }}{{ $x = $y }}{{ $y = . }}{{ end }}{{ $x }}`, "{{$x}}"},
+		{"shadowed declaration", `{{ $x := .Name }}{{ if .On }}{{ $x := bind 1 }}{{ else }}{{ $x := bind 2 }}{{ end }}{{ $x }}`, "{{$x}}"},
+		{"reassigned safely on every path", `{{ $x := .Name }}{{ if .On }}{{ $x = bind 1 }}{{ else }}{{ $x = bind 2 }}{{ end }}{{ $x }}`, ""},
+		{"reassigned safely in range", `{{ $x := bind 1 }}{{ range .Names }}{{ $x = bind . }}{{ end }}{{ $x }}`, ""},
+		{"declared in if", `{{ with bind 1 }}{{ if $x := $.Name }}{{ $x }}{{ end }}{{ end }}`, "{{$x}}"},
+		{"declared in if else", `{{ with bind 1 }}{{ if $x := $.Name }}{{ else }}{{ $x }}{{ end }}{{ end }}`, "{{$x}}"},
+		{"assigned in if", `{{ $x := bind 1 }}{{ if $x = .Name }}{{ end }}{{ $x }}`, "{{$x}}"},
+		{"declared in if from helper", `{{ if $x := bindIf .On .ID }}AND id = {{ $x }}{{ end }}`, ""},
+		{"dot in if with helper condition", `{{ with bind 1 }}{{ if $x := $.Name }}{{ . }}{{ end }}{{ end }}`, ""},
+		{"insert clause fields", `{{ $ins := insert .User "id" }}INSERT INTO users ({{ $ins.Columns }}) VALUES ({{ $ins.Values }})`, ""},
+		{"insert clause chain", `INSERT INTO users ({{ (insert .User).Columns }})`, ""},
+		{"insert clause with", `{{ with insert .User }}({{ .Columns }}) VALUES ({{ .Values }}){{ end }}`, ""},
+		{"data variable field", `{{ $u := .User }}{{ $u.Name }}`, "{{$u.Name}}"},
+		{"root variable field", `{{ $.User.Name }}`, "{{$.User.Name}}"},
+		{"data chain field", `{{ (index .Users 0).Name }}`, "{{(index .Users 0).Name}}"},
+		{"data field in with", `{{ with .User }}{{ .Name }}{{ end }}`, "{{.Name}}"},
+		{"where helpers", `{{ where (eqNullable "a" .ID) (bindIf .On .ID) "b IS NULL" }}`, ""},
+		{"where piped helper", `{{ eqNullable "a" .ID | where }}`, ""},
+		{"sqlNot helper", `{{ sqlNot (in "a" .Names) }}`, ""},
+		{"union helpers", `{{ union .On "SELECT 1" (printf "%s" "SELECT 2" | bind) }}`, ""},
+		{"paginate data counts", `{{ paginate "SELECT 1" (orderBy .Order) .Limit .ID }}`, ""},
+		{"cte helper", `{{ $q := where (bind .ID) }}{{ withCTE (cte .Name $q) }}`, ""},
+		{"csv helper", `{{ csv ", " .Name "b" }}`, ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data := map[string]any{
+				"ID": 1, "Name": "x", "On": true, "Limit": 5, "Cols": []string{"a"}, "Names": []string{"a"},
+				"X": "1=1; DROP TABLE users; --", "User": insertUser{ID: 1, Name: "ann"}, "Users": []insertUser{{Name: "bo"}}, "Order": []OrderTerm{{Column: "id", Direction: SortAsc}},
+			}
+			r := NewRenderer(DialectPostgres).AddFunc("tenant", func() string { return "tenant_id = 1" })
+			if _, _, err := r.FromString(tt.tmpl, data); err != nil {
+				t.Fatalf("unexpected error without strict interpolation: %v", err)
+			}
+
+			_, _, err := r.SetStrictInterpolation(true).FromString(tt.tmpl, data)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRendererStrictInterpolationExtraFuncs(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres).SetStrictInterpolation(true)
+	extra := template.FuncMap{"scope": func() string { return "deleted_at IS NULL" }}
+	out, _, err := r.FromStringWithFuncs(`{{ where scope (bind .ID) }}`, map[string]any{"ID": 1}, DialectPostgres, extra)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "WHERE (deleted_at IS NULL) AND ($1)"; out != want {
+		t.Fatalf("sql mismatch: got %q, want %q", out, want)
+	}
+	if _, _, err := r.FromString(`{{ where .X }}`, map[string]any{"X": "1=1"}); err == nil {
+		t.Fatal("expected error for where over template data")
+	}
+}
+
+func TestStrictInterpolationCoversHelpers(t *testing.T) {
+	t.Parallel()
+
+	// Helpers that hand template data back unchanged and are deliberately
+	// left off both lists.
+	passthrough := map[string]bool{"ctxValue": true, "default": true, "coalesceVal": true}
+
+	r := NewRenderer(DialectPostgres)
+	for name := range r.funcMap(context.Background(), r.newQueryArgs(DialectPostgres)) {
+		_, fragment := fragmentFuncs[name]
+		if !boundFuncs[name] && !fragment && !passthrough[name] {
+			t.Fatalf("helper %q is not classified for strict interpolation", name)
+		}
+	}
+}
+
+func TestRendererStrictInterpolationPreload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ok.sql"), []byte(`SELECT {{ bind .ID }}`), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "raw.sql"), []byte("SELECT 1\nWHERE name = {{ .Name }}"), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	r := NewRenderer(DialectPostgres).AddSearchPath(dir)
+	if err := r.PreloadAll(); err != nil {
+		t.Fatalf("unexpected error without strict interpolation: %v", err)
+	}
+
+	err := r.SetStrictInterpolation(true).PreloadAll()
+	if err == nil || !strings.Contains(err.Error(), "raw.sql:2:") {
+		t.Fatalf("expected error naming the file and line, got %v", err)
+	}
+
+	if _, err := r.RenderTemplate("raw.sql", map[string]any{"Name": "x"}, DialectPostgres); err == nil {
+		t.Fatal("expected render error")
+	}
+}
@@ -0,0 +1,163 @@
+package sqlrender
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formatLiteral renders v as a SQL literal for the supplied dialect so debug
+// output can be pasted into that engine's console. time.Time, []byte and bool
+// values use dialect-specific forms. It backs the debug renderers only and is
+// not a replacement for parameter binding.
+func formatLiteral(dialect Dialect, v any) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return quoteString(dialect, fmt.Sprint(v))
+		}
+		v = val
+	}
+
+	if v == nil {
+		return "NULL"
+	}
+
+	switch val := v.(type) {
+	case string:
+		return quoteString(dialect, val)
+	case []byte:
+		return bytesLiteral(dialect, val)
+	case bool:
+		return boolLiteral(dialect, val)
+	case time.Time:
+		return timeLiteral(dialect, val)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits())
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return formatLiteral(dialect, rv.Elem().Interface())
+	default:
+		return quoteString(dialect, fmt.Sprint(v))
+	}
+}
+
+// timeLiteral renders t in its own location, using TO_DATE/TO_TIMESTAMP on
+// Oracle where plain strings are not implicitly converted.
+func timeLiteral(dialect Dialect, t time.Time) string {
+	switch dialect {
+	case DialectOracle:
+		if t.Nanosecond() == 0 {
+			return "TO_DATE(" + quoteString(dialect, t.Format("2006-01-02 15:04:05")) + ", 'YYYY-MM-DD HH24:MI:SS')"
+		}
+		return "TO_TIMESTAMP(" + quoteString(dialect, t.Format("2006-01-02 15:04:05.000000000")) + ", 'YYYY-MM-DD HH24:MI:SS.FF9')"
+	case DialectSQLServer:
+		return quoteString(dialect, t.Format("2006-01-02T15:04:05.9999999"))
+	default:
+		return quoteString(dialect, t.Format("2006-01-02 15:04:05.999999"))
+	}
+}
+
+// bytesLiteral renders b as a hexadecimal binary literal.
+func bytesLiteral(dialect Dialect, b []byte) string {
+	h := hex.EncodeToString(b)
+	switch dialect {
+	case DialectPostgres:
+		return `'\x` + h + `'::bytea`
+	case DialectSQLServer:
+		return "0x" + h
+	case DialectOracle:
+		return "HEXTORAW('" + h + "')"
+	default:
+		return "X'" + h + "'" // MySQL, SQLite, Snowflake
+	}
+}
+
+// StringLiteral renders s as a quoted SQL string literal for the dialect. It
+// is a last resort for the few places where placeholders are not allowed,
+// such as COMMENT ON, some DDL and SET statements; everywhere else use bind.
+// Quotes are doubled; on MySQL and Snowflake, which treat backslash as an
+// escape character, backslashes are doubled too, and on Postgres a string
+// containing a backslash becomes an E'...' literal so it reads the same
+// whatever standard_conforming_strings is set to. MySQL servers running with
+// NO_BACKSLASH_ESCAPES would see doubled backslashes. Strings containing a
+// NUL byte cannot be represented safely and panic.
+func (qa *QueryArgs) StringLiteral(s string) string {
+	if strings.IndexByte(s, 0) >= 0 {
+		panic("sqlrender: string literal contains a NUL byte")
+	}
+	return quoteString(qa.dialect, s)
+}
+
+// quoteString wraps s in single quotes, doubling embedded quotes and escaping
+// backslashes where the dialect treats them specially (see StringLiteral).
+func quoteString(dialect Dialect, s string) string {
+	quoted := strings.ReplaceAll(s, "'", "''")
+	switch dialect {
+	case DialectMySQL, DialectSnowflake:
+		return "'" + strings.ReplaceAll(quoted, `\`, `\\`) + "'"
+	case DialectPostgres:
+		if strings.Contains(quoted, `\`) {
+			return "E'" + strings.ReplaceAll(quoted, `\`, `\\`) + "'"
+		}
+	}
+	return "'" + quoted + "'"
+}
+
+// scalarLiteral renders v as a SQL literal, accepting only strings, booleans,
+// numbers and nil. Helpers that splice author-supplied constants into SQL use
+// it to keep arbitrary values (structs, slices, ...) out of the statement.
+func scalarLiteral(dialect Dialect, v any) (string, error) {
+	if v == nil {
+		return "NULL", nil
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return formatLiteral(dialect, v), nil
+	default:
+		return "", fmt.Errorf("sqlrender: unsupported literal type %T", v)
+	}
+}
+
+// Bool renders b as the dialect's boolean literal: TRUE/FALSE on Postgres,
+// SQLite and Snowflake, and 1/0 on MySQL, SQL Server and Oracle. Nothing is
+// bound.
+func (qa *QueryArgs) Bool(b bool) string {
+	return boolLiteral(qa.dialect, b)
+}
+
+// boolLiteral renders b for the dialect: TRUE/FALSE where a boolean type
+// exists and 1/0 for engines that model booleans as BIT, TINYINT(1) or
+// NUMBER(1).
+func boolLiteral(dialect Dialect, b bool) string {
+	switch dialect {
+	case DialectMySQL, DialectSQLServer, DialectOracle:
+		if b {
+			return "1"
+		}
+		return "0"
+	default:
+		if b {
+			return "TRUE"
+		}
+		return "FALSE" // Postgres, SQLite, Snowflake
+	}
+}
@@ -0,0 +1,207 @@
+package sqlrender
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stringValuer string
+
+func (v stringValuer) Value() (driver.Value, error) { return string(v), nil }
+
+func TestFormatLiteral(t *testing.T) {
+	t.Parallel()
+
+	n := 5
+	var nilPtr *int
+
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"nil", nil, "NULL"},
+		{"string", "O'Brien", `'O''Brien'`},
+		{"int", 42, "42"},
+		{"uint", uint8(7), "7"},
+		{"float", 1.5, "1.5"},
+		{"bool", true, "TRUE"},
+		{"bytes", []byte{0x01, 0xab}, `'\x01ab'::bytea`},
+		{"time", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), `'2024-01-02 15:04:05'`},
+		{"pointer", &n, "5"},
+		{"nil pointer", nilPtr, "NULL"},
+		{"valuer", stringValuer("x"), `'x'`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := formatLiteral(DialectPostgres, tt.value); got != tt.want {
+				t.Fatalf("literal mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatLiteralDialects(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	tsFrac := time.Date(2024, 1, 2, 15, 4, 5, 500000000, time.UTC)
+	bin := []byte{0x01, 0xab}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		value   any
+		want    string
+	}{
+		{"mysql time", DialectMySQL, ts, `'2024-01-02 15:04:05'`},
+		{"postgres fractional time", DialectPostgres, tsFrac, `'2024-01-02 15:04:05.5'`},
+		{"sqlserver time", DialectSQLServer, ts, `'2024-01-02T15:04:05'`},
+		{"oracle time", DialectOracle, ts, `TO_DATE('2024-01-02 15:04:05', 'YYYY-MM-DD HH24:MI:SS')`},
+		{"oracle fractional time", DialectOracle, tsFrac, `TO_TIMESTAMP('2024-01-02 15:04:05.500000000', 'YYYY-MM-DD HH24:MI:SS.FF9')`},
+		{"mysql bytes", DialectMySQL, bin, `X'01ab'`},
+		{"sqlite bytes", DialectSQLite, bin, `X'01ab'`},
+		{"sqlserver bytes", DialectSQLServer, bin, `0x01ab`},
+		{"oracle bytes", DialectOracle, bin, `HEXTORAW('01ab')`},
+		{"mysql bool", DialectMySQL, true, `1`},
+		{"oracle bool", DialectOracle, false, `0`},
+		{"sqlite bool", DialectSQLite, false, `FALSE`},
+		{"sqlserver nil", DialectSQLServer, nil, `NULL`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := formatLiteral(tt.dialect, tt.value); got != tt.want {
+				t.Fatalf("literal mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryArgsStringLiteral(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect Dialect
+		in      string
+		want    string
+	}{
+		{DialectPostgres, "plain", `'plain'`},
+		{DialectPostgres, "O'Brien", `'O''Brien'`},
+		{DialectPostgres, `C:\path\it's`, `E'C:\\path\\it''s'`},
+		{DialectMySQL, `a\'b`, `'a\\''b'`},
+		{DialectSnowflake, `x\y`, `'x\\y'`},
+		{DialectSQLite, `x\y'z`, `'x\y''z'`},
+		{DialectSQLServer, `x\y'z`, `'x\y''z'`},
+		{DialectOracle, `x\y'z`, `'x\y''z'`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.dialect)+" "+tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(tt.dialect)
+			if got := qa.StringLiteral(tt.in); got != tt.want {
+				t.Fatalf("literal mismatch: got %q, want %q", got, tt.want)
+			}
+			if len(qa.args) != 0 {
+				t.Fatalf("expected no args, got %v", qa.args)
+			}
+		})
+	}
+}
+
+func TestRendererStringLiteral(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	sql, args, err := r.FromString(`COMMENT ON TABLE users IS {{ stringLit .Comment }}`, map[string]any{"Comment": "Bob's table"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `COMMENT ON TABLE users IS 'Bob''s table'`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if len(args) != 0 {
+		t.Fatalf("expected no args, got %v", args)
+	}
+
+	if _, _, err := r.FromString(`{{ stringLit .Comment }}`, map[string]any{"Comment": "a\x00b"}); err == nil {
+		t.Fatal("expected error for NUL byte")
+	}
+}
+
+func TestQueryArgsBool(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect   Dialect
+		wantTrue  string
+		wantFalse string
+	}{
+		{DialectPostgres, "TRUE", "FALSE"},
+		{DialectSQLite, "TRUE", "FALSE"},
+		{DialectSnowflake, "TRUE", "FALSE"},
+		{DialectMySQL, "1", "0"},
+		{DialectSQLServer, "1", "0"},
+		{DialectOracle, "1", "0"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(tt.dialect)
+			if got := qa.Bool(true); got != tt.wantTrue {
+				t.Fatalf("true mismatch: got %q, want %q", got, tt.wantTrue)
+			}
+			if got := qa.Bool(false); got != tt.wantFalse {
+				t.Fatalf("false mismatch: got %q, want %q", got, tt.wantFalse)
+			}
+		})
+	}
+}
+
+func TestRendererSetOracleNumericBools(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `UPDATE t SET a = {{ bind .A }}, b = {{ boolLit .B }} WHERE c IN {{ bind .C }}`
+	data := map[string]any{"A": true, "B": false, "C": []bool{true, false}}
+
+	r := NewRenderer(DialectOracle)
+	_, args, err := r.FromString(tmpl, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []any{true, true, false}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("default args mismatch: got %v, want %v", args, want)
+	}
+
+	sql, args, err := r.SetOracleNumericBools(true).FromString(tmpl, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `UPDATE t SET a = :1, b = 0 WHERE c IN (:2, :3)`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{1, 1, 0}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("numeric args mismatch: got %v, want %v", args, want)
+	}
+
+	_, args, err = r.FromStringWithDialect(tmpl, data, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []any{true, true, false}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("postgres args mismatch: got %v, want %v", args, want)
+	}
+}
@@ -0,0 +1,61 @@
+package sqlrender
+
+import "fmt"
+
+// defaultMaxArgs returns the bound-argument limit of dialect's server or
+// driver, or 0 when there is no practical limit.
+func defaultMaxArgs(dialect Dialect) int {
+	switch dialect {
+	case DialectPostgres, DialectMySQL, DialectOracle:
+		// Oracle's 1000 limit applies to the expressions of one IN list,
+		// not to the statement; inChunked keeps lists under it.
+		return 65535
+	case DialectSQLServer:
+		return 2100
+	case DialectSQLite:
+		return 32766
+	default:
+		return 0 // Snowflake and registered dialects
+	}
+}
+
+// SetMaxArgs caps the number of arguments a single render may bind. Exceeding
+// it fails the render with an error naming the dialect and the limit, instead
+// of a confusing driver error at execution time. Zero (the default) applies
+// the dialect's own limit: 2100 for SQL Server, 32766 for SQLite and 65535
+// for Postgres, MySQL and Oracle. A negative n disables the check.
+func (r *Renderer) SetMaxArgs(n int) *Renderer {
+	r.maxArgs = n
+	return r
+}
+
+// warnNearArgLimit records a warning when the argument about to be bound is
+// the one that reaches 90% of the effective limit, so a statement that is
+// creeping towards it shows up before it starts failing.
+func (qa *QueryArgs) warnNearArgLimit() {
+	if !qa.warningsEnabled || qa.inline || qa.maxArgs < 0 {
+		return
+	}
+	limit := qa.maxArgs
+	if limit == 0 {
+		limit = defaultMaxArgs(qa.dialect)
+	}
+	if n := len(qa.args) + 1; limit > 0 && n == limit*9/10 {
+		qa.warnf("bound %d arguments for %s, near its limit of %d", n, qa.dialect, limit)
+	}
+}
+
+// checkArgLimit panics once binding one more argument would exceed the
+// effective limit. Debug renders inline their values and are never limited.
+func (qa *QueryArgs) checkArgLimit() {
+	if qa.inline || qa.maxArgs < 0 {
+		return
+	}
+	limit := qa.maxArgs
+	if limit == 0 {
+		limit = defaultMaxArgs(qa.dialect)
+	}
+	if limit > 0 && len(qa.args) >= limit {
+		panic(fmt.Sprintf("sqlrender: %s allows at most %d bound arguments; split large IN lists into batches", qa.dialect, limit))
+	}
+}
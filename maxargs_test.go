@@ -0,0 +1,48 @@
+package sqlrender
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRendererSetMaxArgs(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{"IDs": []int{1, 2, 3}}
+	const tmpl = `SELECT * FROM t WHERE id IN {{ bind .IDs }}`
+
+	r := NewRenderer(DialectOracle).SetMaxArgs(2)
+	_, _, err := r.FromString(tmpl, data)
+	if err == nil || !strings.Contains(err.Error(), "oracle allows at most 2 bound arguments") {
+		t.Fatalf("expected max args error, got %v", err)
+	}
+
+	if _, _, err := r.SetMaxArgs(3).FromString(tmpl, data); err != nil {
+		t.Fatalf("unexpected error at the limit: %v", err)
+	}
+
+	if _, err := r.SetMaxArgs(1).FromStringDebug(tmpl, data, DialectOracle); err != nil {
+		t.Fatalf("debug renders should not be limited: %v", err)
+	}
+}
+
+func TestRendererMaxArgsDialectDefault(t *testing.T) {
+	t.Parallel()
+
+	ids := make([]int, 2101)
+	data := map[string]any{"IDs": ids}
+	const tmpl = `{{ bind .IDs }}`
+
+	r := NewRenderer(DialectSQLServer)
+	_, _, err := r.FromString(tmpl, data)
+	if err == nil || !strings.Contains(err.Error(), "sqlserver allows at most 2100") {
+		t.Fatalf("expected SQL Server default limit error, got %v", err)
+	}
+
+	if _, _, err := r.FromStringWithDialect(tmpl, data, DialectPostgres); err != nil {
+		t.Fatalf("unexpected error under Postgres limit: %v", err)
+	}
+	if _, _, err := r.SetMaxArgs(-1).FromString(tmpl, data); err != nil {
+		t.Fatalf("negative limit should disable the check: %v", err)
+	}
+}
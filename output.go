@@ -0,0 +1,42 @@
+package sqlrender
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetMaxOutputSize caps the size of a single render's output in bytes, as
+// produced by the template before minification. A render that exceeds it,
+// say through a runaway range or recursive {{ template }}, aborts with an
+// error instead of growing without bound. Output streamed by FromStringTo
+// before the limit is hit has already reached the writer. Zero (the default)
+// or a negative n means unlimited.
+func (r *Renderer) SetMaxOutputSize(n int) *Renderer {
+	r.maxOutput = n
+	return r
+}
+
+// limitOutput wraps w with the renderer's output cap, if any.
+func (r *Renderer) limitOutput(w io.Writer) io.Writer {
+	if r.maxOutput <= 0 {
+		return w
+	}
+	return &limitedWriter{w: w, remaining: r.maxOutput, limit: r.maxOutput}
+}
+
+// limitedWriter forwards writes to w until limit bytes have been written and
+// fails every write that would go past it.
+type limitedWriter struct {
+	w         io.Writer
+	remaining int
+	limit     int
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > lw.remaining {
+		return 0, fmt.Errorf("sqlrender: rendered output exceeds the limit of %d bytes", lw.limit)
+	}
+	n, err := lw.w.Write(p)
+	lw.remaining -= n
+	return n, err
+}
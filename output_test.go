@@ -0,0 +1,39 @@
+package sqlrender
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRendererSetMaxOutputSize(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM t WHERE id IN ({{ range $i, $id := .IDs }}{{ if $i }}, {{ end }}{{ bind $id }}{{ end }})`
+	data := map[string]any{"IDs": []int{1, 2, 3}}
+
+	r := NewRenderer(DialectPostgres).SetMaxOutputSize(len(`SELECT * FROM t WHERE id IN ($1, $2, $3)`))
+	sql, _, err := r.FromString(tmpl, data)
+	if err != nil {
+		t.Fatalf("unexpected error at the limit: %v", err)
+	}
+	if want := `SELECT * FROM t WHERE id IN ($1, $2, $3)`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+
+	data["IDs"] = []int{1, 2, 3, 4}
+	for _, renderer := range []*Renderer{r, r.Clone().SetMinify(true)} {
+		if _, _, err := renderer.FromString(tmpl, data); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+			t.Fatalf("expected output limit error, got %v", err)
+		}
+	}
+
+	var sb strings.Builder
+	if _, err := r.FromStringTo(&sb, tmpl, data, DialectPostgres); err == nil {
+		t.Fatal("expected output limit error when streaming")
+	}
+
+	runaway := `{{ define "loop" }}x{{ template "loop" . }}{{ end }}{{ template "loop" . }}`
+	if _, _, err := NewRenderer(DialectPostgres).SetMaxOutputSize(1024).FromString(runaway, nil); err == nil {
+		t.Fatal("expected error for runaway recursion")
+	}
+}
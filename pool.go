@@ -0,0 +1,73 @@
+package sqlrender
+
+import (
+	"slices"
+	"sync"
+)
+
+// Reset clears the bound arguments, recorded positional names and profiling
+// timings so the binder can be reused for another statement with the same
+// dialect and configuration. The argument slice keeps its backing array:
+// any slice previously returned from it is overwritten by later binds, so
+// copy the arguments out before calling Reset.
+func (qa *QueryArgs) Reset() {
+	clear(qa.args)
+	qa.args = qa.args[:0]
+	qa.names = nil
+	qa.timings = nil
+	qa.template = ""
+	qa.sourcePath = ""
+	qa.warnings = nil
+}
+
+// SetArgsPooling makes the renderer reuse binders from a sync.Pool instead
+// of allocating one per render, which cuts garbage in tight loops that
+// render the same fragment many times. The pool is shared by every goroutine
+// using the renderer, so the args returned by FromString, FromTemplate and
+// friends are always a copy that the caller owns; only the binder and its
+// working slice are reused. Statements returned by RenderString and
+// RenderTemplate never use pooled binders. It is off by default.
+func (r *Renderer) SetArgsPooling(on bool) *Renderer {
+	if !on {
+		r.pool = nil
+		return r
+	}
+	if r.pool == nil {
+		r.pool = &sync.Pool{New: func() any { return new(QueryArgs) }}
+	}
+	return r
+}
+
+// pooledQueryArgs is like newQueryArgs but takes the binder from the pool
+// when pooling is enabled. Pair it with releaseQueryArgs.
+func (r *Renderer) pooledQueryArgs(dialect Dialect) *QueryArgs {
+	if r.pool == nil {
+		return r.newQueryArgs(dialect)
+	}
+
+	qa := r.pool.Get().(*QueryArgs)
+	qa.Reset()
+	*qa = QueryArgs{args: qa.args}
+	r.configureQueryArgs(qa, dialect)
+	return qa
+}
+
+// resultArgs returns the arguments bound into qa for handing to the caller.
+// A pooled binder's slice is reused by the next render on any goroutine, so
+// it is copied out.
+func (r *Renderer) resultArgs(qa *QueryArgs) []any {
+	if r.pool == nil {
+		return qa.args
+	}
+	if len(qa.args) == 0 {
+		return nil
+	}
+	return slices.Clone(qa.args)
+}
+
+// releaseQueryArgs returns qa to the pool, if pooling is enabled.
+func (r *Renderer) releaseQueryArgs(qa *QueryArgs) {
+	if r.pool != nil {
+		r.pool.Put(qa)
+	}
+}
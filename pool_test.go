@@ -0,0 +1,96 @@
+package sqlrender
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestQueryArgsReset(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	qa.Bind(1)
+	qa.BindNamedPositional("id", 2)
+	backing := cap(qa.args)
+
+	qa.Reset()
+	if len(qa.args) != 0 || qa.names != nil {
+		t.Fatalf("expected empty binder after reset, got args %v names %v", qa.args, qa.names)
+	}
+	if cap(qa.args) != backing {
+		t.Fatalf("expected backing array to be reused: cap %d, want %d", cap(qa.args), backing)
+	}
+	if got := qa.Bind("x"); got != "$1" {
+		t.Fatalf("placeholder mismatch after reset: got %q, want %q", got, "$1")
+	}
+	if qa.dialect != DialectPostgres {
+		t.Fatalf("reset should keep the dialect, got %q", qa.dialect)
+	}
+}
+
+func TestRendererSetArgsPooling(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL).
+		SetArgsPooling(true).
+		SetIdentifierTransformer(func(name string) string { return "p_" + name })
+
+	const tmpl = `SELECT * FROM {{ identifier "t" }} WHERE id IN {{ bind .IDs }}`
+	for i := 0; i < 3; i++ {
+		ids := []int{i, i + 1}
+		sql, args, err := r.FromStringWithDialect(tmpl, map[string]any{"IDs": ids}, DialectPostgres)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := `SELECT * FROM "p_t" WHERE id IN ($1, $2)`; sql != want {
+			t.Fatalf("sql mismatch on render %d: got %q, want %q", i, sql, want)
+		}
+		if want := []any{i, i + 1}; !reflect.DeepEqual(args, want) {
+			t.Fatalf("args mismatch on render %d: got %v, want %v", i, args, want)
+		}
+	}
+
+	if out := r.SetArgsPooling(false); out.pool != nil {
+		t.Fatal("expected pooling to be disabled")
+	}
+}
+
+func TestRendererSetArgsPoolingConcurrent(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres).SetArgsPooling(true)
+	const tmpl = `SELECT * FROM t WHERE id IN {{ bind .IDs }}`
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var held [][]any
+			for i := 0; i < 200; i++ {
+				_, args, err := r.FromString(tmpl, map[string]any{"IDs": []int{g, i}})
+				if err != nil {
+					errs <- err
+					return
+				}
+				held = append(held, args)
+			}
+			// Args from earlier renders must survive later renders on any
+			// goroutine.
+			for i, args := range held {
+				if want := []any{g, i}; !reflect.DeepEqual(args, want) {
+					errs <- fmt.Errorf("goroutine %d render %d: got %v, want %v", g, i, args, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,215 @@
+package sqlrender
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// Between renders a range filter on column. When both bounds are present it
+// emits `"col" BETWEEN lo AND hi`; when only one is, it falls back to `>=` or
+// `<=` so optional request parameters can build open-ended ranges. With no
+// bounds at all it returns the always-true predicate `1 = 1`. Nil pointers
+// count as missing bounds.
+func (qa *QueryArgs) Between(column string, lo, hi any) string {
+	col := qa.mustIdentifier(column)
+
+	switch {
+	case isNil(lo) && isNil(hi):
+		return "1 = 1"
+	case isNil(hi):
+		return col + " >= " + qa.Bind(lo)
+	case isNil(lo):
+		return col + " <= " + qa.Bind(hi)
+	default:
+		return col + " BETWEEN " + qa.Bind(lo) + " AND " + qa.Bind(hi)
+	}
+}
+
+// In renders `"col" IN (...)`, binding every element of values. Unlike bind,
+// an empty (or nil) list yields the always-false predicate `1 = 0` instead of
+// `IN (NULL)`, so the surrounding boolean logic stays valid; with the
+// EmptyInError policy it fails the render instead. A non-list value is bound
+// as a single-element list.
+func (qa *QueryArgs) In(column string, values any) string {
+	return qa.inPredicate(column, values, "IN", "1 = 0")
+}
+
+// NotIn is the negated form of In; an empty list yields the always-true
+// predicate `1 = 1`.
+func (qa *QueryArgs) NotIn(column string, values any) string {
+	return qa.inPredicate(column, values, "NOT IN", "1 = 1")
+}
+
+// InChunked is like In but splits values into IN lists of at most chunkSize
+// elements joined with OR, e.g. `("id" IN ($1, $2) OR "id" IN ($3))`, so very
+// large lists stay under per-list limits such as Oracle's 1000 expressions.
+// Placeholders stay sequential across chunks. An empty list yields `1 = 0`;
+// a chunkSize below 1 panics.
+func (qa *QueryArgs) InChunked(column string, values any, chunkSize int) string {
+	if chunkSize < 1 {
+		panic(fmt.Sprintf("sqlrender: inChunked chunk size must be positive, got %d", chunkSize))
+	}
+
+	v := reflect.ValueOf(values)
+	if !v.IsValid() || !isList(v) || v.Len() <= chunkSize {
+		return qa.In(column, values)
+	}
+
+	col := qa.mustIdentifier(column)
+	chunks := make([]string, 0, (v.Len()+chunkSize-1)/chunkSize)
+	for start := 0; start < v.Len(); start += chunkSize {
+		end := min(start+chunkSize, v.Len())
+		placeholders := make([]string, 0, end-start)
+		for i := start; i < end; i++ {
+			placeholders = append(placeholders, qa.bindScalar(v.Index(i)))
+		}
+		chunks = append(chunks, col+" IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	return "(" + strings.Join(chunks, " OR ") + ")"
+}
+
+func (qa *QueryArgs) inPredicate(column string, values any, op, empty string) string {
+	col := qa.mustIdentifier(column)
+
+	v := reflect.ValueOf(values)
+	switch {
+	case !v.IsValid() || isList(v) && v.Len() == 0:
+		if qa.emptyIn == EmptyInError {
+			panic(fmt.Sprintf("sqlrender: empty list for %s %s", col, op))
+		}
+		return empty
+	case !isList(v):
+		return col + " " + op + " (" + qa.Bind(values) + ")"
+	default:
+		return col + " " + op + " " + qa.bindInList(v)
+	}
+}
+
+// BoolEq renders an equality test against a boolean literal, e.g.
+// `"active" = TRUE` on Postgres and `"active" = 1` on MySQL, SQL Server and
+// Oracle where booleans are stored as TINYINT/BIT/NUMBER. Nothing is bound.
+func (qa *QueryArgs) BoolEq(column string, value bool) string {
+	return qa.mustIdentifier(column) + " = " + boolLiteral(qa.dialect, value)
+}
+
+// EqNullable renders a NULL-aware equality test: `"col" IS NULL` when value is
+// nil, a nil pointer or a driver.Valuer whose value is nil (such as an invalid
+// sql.NullString), and `"col" = $1` otherwise. Plain `col = NULL` never
+// matches, which silently drops rows from optional filters.
+func (qa *QueryArgs) EqNullable(column string, value any) string {
+	col := qa.mustIdentifier(column)
+	if isNullValue(value) {
+		return col + " IS NULL"
+	}
+	return col + " = " + qa.Bind(value)
+}
+
+// NeNullable is the negated form of EqNullable, rendering `IS NOT NULL` or
+// `<> $1`.
+func (qa *QueryArgs) NeNullable(column string, value any) string {
+	col := qa.mustIdentifier(column)
+	if isNullValue(value) {
+		return col + " IS NOT NULL"
+	}
+	return col + " <> " + qa.Bind(value)
+}
+
+// EqAll renders an equality test for every entry of filters, in sorted key
+// order and joined with AND: `"a" = $1 AND "b" IS NULL`. Each pair goes
+// through EqNullable, so nil values render IS NULL. An empty map renders an
+// empty string, leaving the caller to decide whether to emit WHERE at all.
+func (qa *QueryArgs) EqAll(filters map[string]any) string {
+	parts := make([]string, 0, len(filters))
+	for _, key := range sortedKeys(filters) {
+		parts = append(parts, qa.EqNullable(key, filters[key]))
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// Like renders a substring match, `"col" LIKE <placeholder>`, binding
+// `%term%` with the LIKE wildcards `%` and `_` in term (and `[` on SQL
+// Server) escaped, so user input always matches literally. escape optionally
+// sets the escape character, `\` by default; occurrences of it in term are
+// doubled.
+//
+// Because the pattern is bound rather than inlined, string-literal escaping
+// never applies to it, only LIKE's own escape rule. MySQL and Postgres
+// already treat `\` as the LIKE escape, so the clause is omitted there for the
+// default; every other combination gets an explicit `ESCAPE '<c>'`. An escape
+// that is not a single character, or is itself a wildcard, panics.
+func (qa *QueryArgs) Like(column, term string, escape ...string) string {
+	esc := `\`
+	switch len(escape) {
+	case 0:
+	case 1:
+		esc = escape[0]
+	default:
+		panic(fmt.Sprintf("sqlrender: like accepts one escape character, got %d", len(escape)))
+	}
+	if utf8.RuneCountInString(esc) != 1 || esc == "%" || esc == "_" {
+		panic(fmt.Sprintf("sqlrender: invalid like escape character %q", esc))
+	}
+
+	col := qa.mustIdentifier(column)
+	pattern := "%" + escapeLike(qa.dialect, term, esc) + "%"
+	sql := col + " LIKE " + qa.Bind(pattern)
+
+	if esc == `\` && (qa.dialect == DialectMySQL || qa.dialect == DialectPostgres) {
+		return sql
+	}
+	return sql + " ESCAPE " + quoteString(qa.dialect, esc)
+}
+
+// escapeLike prefixes every escape character and LIKE wildcard in term with
+// esc.
+func escapeLike(dialect Dialect, term, esc string) string {
+	special := "%_"
+	if dialect == DialectSQLServer {
+		special += "["
+	}
+
+	var sb strings.Builder
+	for _, r := range term {
+		if string(r) == esc || strings.ContainsRune(special, r) {
+			sb.WriteString(esc)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// mustIdentifier quotes name, panicking on invalid input like Identifier does.
+// Unlike Identifier it also rejects the empty string, since helpers that take
+// a column always need one.
+func (qa *QueryArgs) mustIdentifier(name string) string {
+	quoted, err := qa.identifier(name)
+	if err != nil {
+		panic(err.Error())
+	}
+	return quoted
+}
+
+// isNil reports whether v is nil or a nil pointer.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// isNullValue reports whether v binds as SQL NULL: nil, a nil pointer, or a
+// driver.Valuer that yields nil.
+func isNullValue(v any) bool {
+	if isNil(v) {
+		return true
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		return err == nil && value == nil
+	}
+	return false
+}
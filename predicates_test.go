@@ -0,0 +1,368 @@
+package sqlrender
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestQueryArgsBetween(t *testing.T) {
+	t.Parallel()
+
+	var nilBound *int
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		lo, hi   any
+		want     string
+		wantArgs []any
+	}{
+		{"postgres both", DialectPostgres, 1, 10, `"n" BETWEEN $1 AND $2`, []any{1, 10}},
+		{"oracle both", DialectOracle, 1, 10, `"n" BETWEEN :1 AND :2`, []any{1, 10}},
+		{"postgres lo only", DialectPostgres, 1, nil, `"n" >= $1`, []any{1}},
+		{"oracle hi only", DialectOracle, nilBound, 10, `"n" <= :1`, []any{10}},
+		{"no bounds", DialectPostgres, nil, nil, `1 = 1`, nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			qa := NewQueryArgs(tt.dialect)
+			if got := qa.Between("n", tt.lo, tt.hi); got != tt.want {
+				t.Fatalf("between mismatch: got %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestRendererBetweenNumbering(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectOracle)
+	sql, args, err := r.FromString(
+		`WHERE org = {{ bind .Org }} AND {{ between "created_at" .From .To }}`,
+		map[string]any{"Org": 4, "From": "2024-01-01", "To": "2024-02-01"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `WHERE org = :1 AND "created_at" BETWEEN :2 AND :3`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{4, "2024-01-01", "2024-02-01"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	if _, _, err := r.FromString(`{{ between "bad col" 1 2 }}`, nil); err == nil {
+		t.Fatal("expected error for invalid column")
+	}
+}
+
+func TestQueryArgsIn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		values   any
+		wantIn   string
+		wantNot  string
+		wantArgs []any
+	}{
+		{"slice", []int{1, 2}, `"id" IN ($1, $2)`, `"id" NOT IN ($1, $2)`, []any{1, 2}},
+		{"array", [1]string{"a"}, `"id" IN ($1)`, `"id" NOT IN ($1)`, []any{"a"}},
+		{"scalar", 7, `"id" IN ($1)`, `"id" NOT IN ($1)`, []any{7}},
+		{"empty", []int{}, `1 = 0`, `1 = 1`, nil},
+		{"nil", nil, `1 = 0`, `1 = 1`, nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(DialectPostgres)
+			if got := qa.In("id", tt.values); got != tt.wantIn {
+				t.Fatalf("in mismatch: got %q, want %q", got, tt.wantIn)
+			}
+			if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+			}
+
+			qa = NewQueryArgs(DialectPostgres)
+			if got := qa.NotIn("id", tt.values); got != tt.wantNot {
+				t.Fatalf("not in mismatch: got %q, want %q", got, tt.wantNot)
+			}
+			if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestRendererIn(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	sql, args, err := r.FromString(
+		`WHERE active AND ({{ in "id" .IDs }} OR {{ notIn "org" .Orgs }})`,
+		map[string]any{"IDs": []int{}, "Orgs": []int{3}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "WHERE active AND (1 = 0 OR `org` NOT IN (?))"; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{3}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsBoolEq(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect   Dialect
+		wantTrue  string
+		wantFalse string
+	}{
+		{DialectPostgres, `"active" = TRUE`, `"active" = FALSE`},
+		{DialectSQLite, `"active" = TRUE`, `"active" = FALSE`},
+		{DialectSnowflake, `"active" = TRUE`, `"active" = FALSE`},
+		{DialectMySQL, "`active` = 1", "`active` = 0"},
+		{DialectSQLServer, `[active] = 1`, `[active] = 0`},
+		{DialectOracle, `"active" = 1`, `"active" = 0`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			t.Parallel()
+			qa := NewQueryArgs(tt.dialect)
+			if got := qa.BoolEq("active", true); got != tt.wantTrue {
+				t.Fatalf("true mismatch: got %q, want %q", got, tt.wantTrue)
+			}
+			if got := qa.BoolEq("active", false); got != tt.wantFalse {
+				t.Fatalf("false mismatch: got %q, want %q", got, tt.wantFalse)
+			}
+			if len(qa.args) != 0 {
+				t.Fatalf("expected no args, got %v", qa.args)
+			}
+		})
+	}
+}
+
+func TestQueryArgsEqNullable(t *testing.T) {
+	t.Parallel()
+
+	var nilPtr *string
+	tests := []struct {
+		name     string
+		value    any
+		wantEq   string
+		wantNe   string
+		wantArgs []any
+	}{
+		{"nil", nil, `"deleted_at" IS NULL`, `"deleted_at" IS NOT NULL`, nil},
+		{"nil pointer", nilPtr, `"deleted_at" IS NULL`, `"deleted_at" IS NOT NULL`, nil},
+		{"null valuer", sql.NullString{}, `"deleted_at" IS NULL`, `"deleted_at" IS NOT NULL`, nil},
+		{"value", "x", `"deleted_at" = $1`, `"deleted_at" <> $2`, []any{"x", "x"}},
+		{"valid valuer", sql.NullString{String: "y", Valid: true}, `"deleted_at" = $1`, `"deleted_at" <> $2`,
+			[]any{sql.NullString{String: "y", Valid: true}, sql.NullString{String: "y", Valid: true}}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(DialectPostgres)
+			if got := qa.EqNullable("deleted_at", tt.value); got != tt.wantEq {
+				t.Fatalf("eq mismatch: got %q, want %q", got, tt.wantEq)
+			}
+			if got := qa.NeNullable("deleted_at", tt.value); got != tt.wantNe {
+				t.Fatalf("ne mismatch: got %q, want %q", got, tt.wantNe)
+			}
+			if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestQueryArgsEqAll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filters  map[string]any
+		wantSQL  string
+		wantArgs []any
+	}{
+		{"sorted keys", map[string]any{"status": "open", "org_id": 7, "assignee": nil},
+			`"assignee" IS NULL AND "org_id" = $1 AND "status" = $2`, []any{7, "open"}},
+		{"single", map[string]any{"id": 1}, `"id" = $1`, []any{1}},
+		{"empty", map[string]any{}, "", nil},
+		{"nil map", nil, "", nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Repeat to catch any dependence on map iteration order.
+			for i := 0; i < 10; i++ {
+				qa := NewQueryArgs(DialectPostgres)
+				if got := qa.EqAll(tt.filters); got != tt.wantSQL {
+					t.Fatalf("sql mismatch: got %q, want %q", got, tt.wantSQL)
+				}
+				if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+					t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
+
+func TestRendererEqNullable(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	sql, args, err := r.FromString(
+		`SELECT * FROM t WHERE {{ eqNullable "parent_id" .Parent }} AND {{ neNullable "owner" .Owner }}`,
+		map[string]any{"Parent": nil, "Owner": 7},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT * FROM t WHERE `parent_id` IS NULL AND `owner` <> ?"; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{7}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsInChunked(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		values   any
+		size     int
+		want     string
+		wantArgs []any
+	}{
+		{"empty", []int{}, 2, "1 = 0", nil},
+		{"nil", nil, 2, "1 = 0", nil},
+		{"single chunk", []int{1, 2}, 2, `"id" IN ($1, $2)`, []any{1, 2}},
+		{"chunks", []int{1, 2, 3, 4, 5}, 2, `("id" IN ($1, $2) OR "id" IN ($3, $4) OR "id" IN ($5))`, []any{1, 2, 3, 4, 5}},
+		{"array", [3]string{"a", "b", "c"}, 1, `("id" IN ($1) OR "id" IN ($2) OR "id" IN ($3))`, []any{"a", "b", "c"}},
+		{"byte arrays", [][16]byte{{1}, {2}, {3}}, 2, `("id" IN ($1, $2) OR "id" IN ($3))`, []any{make16(1), make16(2), make16(3)}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(DialectPostgres)
+			if got := qa.InChunked("id", tt.values, tt.size); got != tt.want {
+				t.Fatalf("predicate mismatch: got %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestRendererInChunkedNumbering(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectOracle)
+	sql, args, err := r.FromString(
+		`SELECT * FROM t WHERE a = {{ bind .A }} AND {{ inChunked "id" .IDs 2 }} AND b = {{ bind .B }}`,
+		map[string]any{"A": "x", "IDs": []int{10, 20, 30}, "B": "y"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM t WHERE a = :1 AND ("id" IN (:2, :3) OR "id" IN (:4)) AND b = :5`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{"x", 10, 20, 30, "y"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	if _, _, err := r.FromString(`{{ inChunked "id" .IDs 0 }}`, map[string]any{"IDs": []int{1}}); err == nil {
+		t.Fatal("expected error for non-positive chunk size")
+	}
+}
+
+func TestQueryArgsLike(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		term     string
+		escape   []string
+		want     string
+		wantTerm string
+	}{
+		{"postgres default", DialectPostgres, `50%_off\`, nil, `"name" LIKE $1`, `%50\%\_off\\%`},
+		{"postgres custom escape", DialectPostgres, `50%!`, []string{"!"}, `"name" LIKE $1 ESCAPE '!'`, `%50!%!!%`},
+		{"mysql default", DialectMySQL, `a\b_c`, nil, "`name` LIKE ?", `%a\\b\_c%`},
+		{"mysql custom escape", DialectMySQL, `a\b_c`, []string{"|"}, "`name` LIKE ? ESCAPE '|'", `%a\b|_c%`},
+		{"sqlite", DialectSQLite, `50%`, nil, `"name" LIKE ? ESCAPE '\'`, `%50\%%`},
+		{"snowflake", DialectSnowflake, `50%`, nil, `"name" LIKE ? ESCAPE '\\'`, `%50\%%`},
+		{"sqlserver brackets", DialectSQLServer, `[a]%`, nil, `[name] LIKE @p1 ESCAPE '\'`, `%\[a]\%%`},
+		{"oracle quote escape", DialectOracle, `it's_`, []string{"'"}, `"name" LIKE :1 ESCAPE ''''`, `%it''s'_%`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(tt.dialect)
+			if got := qa.Like("name", tt.term, tt.escape...); got != tt.want {
+				t.Fatalf("predicate mismatch: got %q, want %q", got, tt.want)
+			}
+			if want := []any{tt.wantTerm}; !reflect.DeepEqual(qa.args, want) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+			}
+		})
+	}
+}
+
+func TestRendererLikeErrors(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	for _, tmpl := range []string{
+		`{{ like "name" .Q "" }}`,
+		`{{ like "name" .Q "ab" }}`,
+		`{{ like "name" .Q "%" }}`,
+		`{{ like "name" .Q "!" "#" }}`,
+		`{{ like "na me" .Q }}`,
+	} {
+		if _, _, err := r.FromString(tmpl, map[string]any{"Q": "x"}); err == nil {
+			t.Fatalf("expected error for %s", tmpl)
+		}
+	}
+}
+
+// make16 returns the []byte a [16]byte with first byte b is bound as.
+func make16(b byte) []byte {
+	out := make([]byte, 16)
+	out[0] = b
+	return out
+}
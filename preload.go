@@ -0,0 +1,62 @@
+package sqlrender
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PreloadAll parses every template file reachable through the search paths
+// and returns the first parse error, annotated with the file's path, so
+// broken templates fail at startup or in CI rather than on first use. Plain
+// search paths are read one level deep, like template lookup; recursive ones
+// are walked. Files are recognised by the default extension, or ".sql" when
+// none is set. A search path that does not exist is an error. Nothing is
+// executed and no state is kept: templates are still read on every render.
+func (r *Renderer) PreloadAll() error {
+	ext := r.defaultExtension
+	if ext == "" {
+		ext = ".sql"
+	}
+	qa := r.newQueryArgs(r.defaultDialect)
+
+	var paths []string
+	for _, dir := range r.searchPaths {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("sqlrender: failed to read search path %q: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ext) {
+				paths = append(paths, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	for _, root := range r.recursivePaths {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, ext) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("sqlrender: failed to walk search path %q: %w", root, err)
+		}
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("sqlrender: failed to read %q: %w", path, err)
+		}
+		if _, err := r.parse(context.Background(), path, string(content), qa); err != nil {
+			return fmt.Errorf("sqlrender: template %q: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package sqlrender
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRendererPreloadAll(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	nested := t.TempDir()
+	files := map[string]string{
+		filepath.Join(dir, "ok.sql"):                 `SELECT {{ bind .ID }}`,
+		filepath.Join(dir, "notes.txt"):              `{{ broken`,
+		filepath.Join(nested, "users", "find.sql"):   `SELECT * FROM {{ table "users" }} WHERE id = {{ lookup .ID }}`,
+		filepath.Join(nested, "orders", "list.sql"):  `SELECT * FROM orders`,
+		filepath.Join(dir, "sub", "ignored.sql"):     `{{ broken`,
+		filepath.Join(nested, "orders", "skip.tmpl"): `{{ broken`,
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write template: %v", err)
+		}
+	}
+
+	r := NewRenderer(DialectPostgres).AddSearchPath(dir).AddSearchPathRecursive(nested)
+	err := r.PreloadAll()
+	if err == nil || !strings.Contains(err.Error(), filepath.Join("users", "find.sql")) {
+		t.Fatalf("expected parse error naming the file, got %v", err)
+	}
+
+	r.AddFunc("lookup", func(v any) string { return "" })
+	if err := r.PreloadAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := NewRenderer(DialectPostgres).AddSearchPath(filepath.Join(dir, "missing")).PreloadAll(); err == nil {
+		t.Fatal("expected error for missing search path")
+	}
+}
@@ -0,0 +1,40 @@
+package sqlrender
+
+import (
+	"reflect"
+	"time"
+)
+
+// SetProfiling enables or disables per-function timing of custom template
+// functions. When enabled, every function registered with AddFunc/AddFuncs is
+// wrapped so the cumulative time spent in it during a render is recorded and
+// exposed on Statement.Timings. Profiling adds reflection overhead to each
+// call and is off by default.
+func (r *Renderer) SetProfiling(enabled bool) *Renderer {
+	r.profiling = enabled
+	return r
+}
+
+// timed wraps fn so each call adds its duration to qa.timings[name]. Values
+// that are not functions are returned unchanged and left for text/template to
+// reject.
+func (qa *QueryArgs) timed(name string, fn any) any {
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func {
+		return fn
+	}
+
+	if qa.timings == nil {
+		qa.timings = make(map[string]time.Duration)
+	}
+
+	return reflect.MakeFunc(rv.Type(), func(args []reflect.Value) []reflect.Value {
+		start := time.Now()
+		defer func() { qa.timings[name] += time.Since(start) }()
+
+		if rv.Type().IsVariadic() {
+			return rv.CallSlice(args)
+		}
+		return rv.Call(args)
+	}).Interface()
+}
@@ -0,0 +1,55 @@
+package sqlrender
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRendererProfiling(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	if out := r.SetProfiling(true); out != r {
+		t.Fatal("SetProfiling should return renderer instance")
+	}
+	r.AddFunc("slow", func() string {
+		time.Sleep(5 * time.Millisecond)
+		return "1"
+	})
+	r.AddFunc("join", func(sep string, parts ...string) string {
+		return strings.Join(parts, sep)
+	})
+
+	stmt, err := r.RenderString(`SELECT {{ slow }}, {{ slow }}, '{{ join "-" "a" "b" }}'`, nil, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT 1, 1, 'a-b'`; stmt.SQL != want {
+		t.Fatalf("sql mismatch: got %q, want %q", stmt.SQL, want)
+	}
+	if got := stmt.Timings["slow"]; got < 10*time.Millisecond {
+		t.Fatalf("expected slow func timing of at least 10ms, got %v", got)
+	}
+	if _, ok := stmt.Timings["join"]; !ok {
+		t.Fatal("expected timing entry for variadic func")
+	}
+	if _, ok := stmt.Timings["bind"]; ok {
+		t.Fatal("built-in helpers should not be profiled")
+	}
+}
+
+func TestRendererProfilingDisabled(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	r.AddFunc("one", func() string { return "1" })
+
+	stmt, err := r.RenderString(`SELECT {{ one }}`, nil, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stmt.Timings != nil {
+		t.Fatalf("expected no timings without profiling, got %v", stmt.Timings)
+	}
+}
@@ -0,0 +1,185 @@
+package sqlrender
+
+import "strings"
+
+// sqlTokenKind classifies a span of rendered SQL.
+type sqlTokenKind int
+
+const (
+	// tokenText is ordinary SQL: keywords, operators, placeholders and so on.
+	tokenText sqlTokenKind = iota
+	// tokenSpace is a run of whitespace.
+	tokenSpace
+	// tokenString is a single-quoted string literal, quotes included.
+	tokenString
+	// tokenQuoted is a double- or backtick-quoted identifier.
+	tokenQuoted
+	// tokenDollar is a Postgres dollar-quoted body such as $$...$$ or
+	// $fn$...$fn$.
+	tokenDollar
+	// tokenLineComment is a -- comment up to, not including, the newline.
+	tokenLineComment
+	// tokenBlockComment is a /* */ comment.
+	tokenBlockComment
+)
+
+// sqlToken is one span produced by scanSQL.
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// scanSQL splits s into tokens so callers can rewrite SQL without touching the
+// contents of literals, quoted identifiers or comments. MySQL strings honour
+// backslash escapes. An unterminated literal or comment runs to the end of
+// the input. Concatenating the token texts always reproduces s.
+func scanSQL(s string, dialect Dialect) []sqlToken {
+	var tokens []sqlToken
+	textStart := 0
+	flush := func(end int) {
+		if end > textStart {
+			tokens = append(tokens, sqlToken{kind: tokenText, text: s[textStart:end]})
+		}
+	}
+
+	for i := 0; i < len(s); {
+		kind, end := tokenText, i
+		switch c := s[i]; {
+		case isSpace(c):
+			kind, end = tokenSpace, i+1
+			for end < len(s) && isSpace(s[end]) {
+				end++
+			}
+		case c == '\'':
+			kind, end = tokenString, scanQuoted(s, i, '\'', dialect == DialectMySQL)
+		case c == '"' || c == '`':
+			kind, end = tokenQuoted, scanQuoted(s, i, c, false)
+		case c == '-' && strings.HasPrefix(s[i:], "--"):
+			kind, end = tokenLineComment, len(s)
+			if n := strings.IndexByte(s[i:], '\n'); n >= 0 {
+				end = i + n
+			}
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			kind, end = tokenBlockComment, len(s)
+			if n := strings.Index(s[i+2:], "*/"); n >= 0 {
+				end = i + 2 + n + 2
+			}
+		case c == '$':
+			if tag, ok := dollarTag(s[i:]); ok {
+				kind, end = tokenDollar, len(s)
+				if n := strings.Index(s[i+len(tag):], tag); n >= 0 {
+					end = i + len(tag) + n + len(tag)
+				}
+			}
+		}
+
+		if kind == tokenText {
+			i++
+			continue
+		}
+		flush(i)
+		tokens = append(tokens, sqlToken{kind: kind, text: s[i:end]})
+		i, textStart = end, end
+	}
+	flush(len(s))
+	return tokens
+}
+
+// scanQuoted returns the index just past the quoted span opening at s[start].
+// A doubled quote is an escaped quote; with backslash, so is \q.
+func scanQuoted(s string, start int, quote byte, backslash bool) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// dollarTag reports whether s starts with a dollar-quote opener ($$ or
+// $tag$) and returns it. Positional placeholders such as $1 are not tags.
+func dollarTag(s string) (string, bool) {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1], true
+		case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 1:
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// minifySQL drops comments and collapses whitespace to single spaces outside
+// literals, quoted identifiers and dollar-quoted bodies. Optimizer hints
+// (/*+ ... */) and MySQL executable comments (/*! ... */) are kept because
+// they change how the statement runs.
+func minifySQL(s string, dialect Dialect) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	pendingSpace := false
+	for _, tok := range scanSQL(s, dialect) {
+		switch tok.kind {
+		case tokenSpace, tokenLineComment:
+			pendingSpace = true
+			continue
+		case tokenBlockComment:
+			if !strings.HasPrefix(tok.text, "/*+") && !strings.HasPrefix(tok.text, "/*!") {
+				pendingSpace = true
+				continue
+			}
+		}
+		if pendingSpace && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		pendingSpace = false
+		b.WriteString(tok.text)
+	}
+	return b.String()
+}
+
+// trimBlocksSQL drops whitespace-only lines and trailing whitespace outside
+// literals, quoted identifiers and comments, keeping the indentation of the
+// lines that remain. Leading blank lines and trailing whitespace of the whole
+// statement are removed.
+func trimBlocksSQL(s string, dialect Dialect) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	tokens := scanSQL(s, dialect)
+	for i, tok := range tokens {
+		if tok.kind != tokenSpace {
+			b.WriteString(tok.text)
+			continue
+		}
+		if i == len(tokens)-1 {
+			break
+		}
+		n := strings.LastIndexByte(tok.text, '\n')
+		switch {
+		case n < 0:
+			b.WriteString(tok.text)
+		case b.Len() == 0:
+			b.WriteString(tok.text[n+1:])
+		default:
+			b.WriteByte('\n')
+			b.WriteString(tok.text[n+1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,202 @@
+package sqlrender
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanSQLRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	inputs := []string{
+		"SELECT 1",
+		"SELECT '--not a comment' -- real\nFROM t",
+		`SELECT "a""b", 'it''s', $1 FROM t /* c */`,
+		"DO $fn$ BEGIN RAISE NOTICE 'x;'; END $fn$;",
+		"SELECT 'unterminated",
+		"SELECT /* unterminated",
+	}
+	for _, in := range inputs {
+		var b strings.Builder
+		for _, tok := range scanSQL(in, DialectPostgres) {
+			b.WriteString(tok.text)
+		}
+		if got := b.String(); got != in {
+			t.Fatalf("round trip mismatch: got %q, want %q", got, in)
+		}
+	}
+}
+
+func TestScanSQLMySQLBackslash(t *testing.T) {
+	t.Parallel()
+
+	tokens := scanSQL(`SELECT 'it\'s' -- c`, DialectMySQL)
+	var kinds []sqlTokenKind
+	for _, tok := range tokens {
+		kinds = append(kinds, tok.kind)
+	}
+	if tokens[2].kind != tokenString || tokens[2].text != `'it\'s'` {
+		t.Fatalf("string token mismatch: got %q (kinds %v)", tokens[2].text, kinds)
+	}
+	if last := tokens[len(tokens)-1]; last.kind != tokenLineComment {
+		t.Fatalf("expected trailing line comment, got %q", last.text)
+	}
+}
+
+func TestMinifySQL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		in      string
+		want    string
+	}{
+		{
+			name:    "comments and indentation",
+			dialect: DialectPostgres,
+			in:      "-- find users\nSELECT id,\n       name\n  FROM users /* main table */\n WHERE id = $1 -- by id\n",
+			want:    "SELECT id, name FROM users WHERE id = $1",
+		},
+		{
+			name:    "comment markers inside strings",
+			dialect: DialectPostgres,
+			in:      "SELECT '--  keep  /* this */'   AS  x",
+			want:    "SELECT '--  keep  /* this */' AS x",
+		},
+		{
+			name:    "quoted identifier whitespace",
+			dialect: DialectPostgres,
+			in:      `SELECT  "odd   name"  FROM t`,
+			want:    `SELECT "odd   name" FROM t`,
+		},
+		{
+			name:    "dollar quoted body",
+			dialect: DialectPostgres,
+			in:      "CREATE FUNCTION f() RETURNS int AS $$\n  SELECT 1 -- one\n$$ LANGUAGE sql",
+			want:    "CREATE FUNCTION f() RETURNS int AS $$\n  SELECT 1 -- one\n$$ LANGUAGE sql",
+		},
+		{
+			name:    "hints kept",
+			dialect: DialectMySQL,
+			in:      "SELECT /*+ MAX_EXECUTION_TIME(10) */ *\nFROM t",
+			want:    "SELECT /*+ MAX_EXECUTION_TIME(10) */ * FROM t",
+		},
+		{
+			name:    "mysql backslash escape",
+			dialect: DialectMySQL,
+			in:      `SELECT 'a\'  --b'   FROM t`,
+			want:    `SELECT 'a\'  --b' FROM t`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := minifySQL(tt.in, tt.dialect); got != tt.want {
+				t.Fatalf("minify mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimBlocksSQL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		in      string
+		want    string
+	}{
+		{
+			name:    "blank lines from actions",
+			dialect: DialectPostgres,
+			in:      "SELECT *\n  FROM users\n  \n  \n WHERE id = $1  \n",
+			want:    "SELECT *\n  FROM users\n WHERE id = $1",
+		},
+		{
+			name:    "leading blank lines",
+			dialect: DialectPostgres,
+			in:      "\n   \n  SELECT 1",
+			want:    "  SELECT 1",
+		},
+		{
+			name:    "inline spaces kept",
+			dialect: DialectPostgres,
+			in:      "SELECT a,  b FROM t",
+			want:    "SELECT a,  b FROM t",
+		},
+		{
+			name:    "string literal untouched",
+			dialect: DialectPostgres,
+			in:      "SELECT 'a\n\n  b'\n\n FROM t",
+			want:    "SELECT 'a\n\n  b'\n FROM t",
+		},
+		{
+			name:    "comments kept",
+			dialect: DialectMySQL,
+			in:      "SELECT 1 -- one\n\n/* two\n\n */ FROM t",
+			want:    "SELECT 1 -- one\n/* two\n\n */ FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := trimBlocksSQL(tt.in, tt.dialect); got != tt.want {
+				t.Fatalf("trim mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererSetTrimBlocks(t *testing.T) {
+	t.Parallel()
+
+	tmpl := "SELECT *\n  FROM users\n WHERE 1 = 1\n  {{ if .Name }}\n   AND name = {{ bind .Name }}\n  {{ end }}\n  {{ if .Age }}\n   AND age = {{ bind .Age }}\n  {{ end }}\n"
+	data := map[string]any{"Name": "x"}
+
+	r := NewRenderer(DialectPostgres).SetTrimBlocks(true)
+	out, args, err := r.FromString(tmpl, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT *\n  FROM users\n WHERE 1 = 1\n   AND name = $1"; out != want {
+		t.Fatalf("sql mismatch: got %q, want %q", out, want)
+	}
+	if len(args) != 1 || args[0] != "x" {
+		t.Fatalf("args mismatch: got %v", args)
+	}
+}
+
+func TestRendererSetMinify(t *testing.T) {
+	t.Parallel()
+
+	tmpl := "SELECT *\n  FROM users -- all\n WHERE name = {{ bind .Name }}\n"
+	data := map[string]any{"Name": "x -- y"}
+
+	r := NewRenderer(DialectPostgres)
+	sql, _, err := r.FromString(tmpl, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT *\n  FROM users -- all\n WHERE name = $1\n"; sql != want {
+		t.Fatalf("expected unminified output by default, got %q", sql)
+	}
+
+	sql, args, err := r.SetMinify(true).FromString(tmpl, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT * FROM users WHERE name = $1"; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if len(args) != 1 || args[0] != "x -- y" {
+		t.Fatalf("args mismatch: got %v", args)
+	}
+}
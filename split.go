@@ -0,0 +1,201 @@
+package sqlrender
+
+import "strings"
+
+// SplitStatements splits a rendered script into its individual statements on
+// terminating semicolons, dropping the terminators and any blank fragments.
+// Semicolons inside string literals, quoted identifiers, comments and
+// Postgres dollar-quoted bodies ($$ ... $$) do not split. Neither do
+// semicolons inside BEGIN ... END blocks (trigger and procedure bodies), with
+// CASE ... END nesting tracked inside them; a bare `BEGIN;` or
+// `BEGIN TRANSACTION` is treated as a statement of its own. Oracle
+// declaration sections belong to the block they precede: a top-level
+// `DECLARE` and the `IS`/`AS` of `CREATE PROCEDURE`, `FUNCTION` or `PACKAGE`
+// open the block that the following BEGIN ... END (or a package's END)
+// closes. Statements that contain such a block keep their final `;` since
+// some engines, Oracle among them, require it after END. MySQL backslash
+// escapes inside strings are not recognised.
+func SplitStatements(sql string) []string {
+	lexemes := splitLexemes(sql)
+
+	var (
+		out      []string
+		cur      strings.Builder
+		first    string
+		hadBlock bool
+		routine  bool
+		// blocks holds one entry per open block, true while the block is
+		// still in its declaration section waiting for its BEGIN.
+		blocks []bool
+	)
+	emit := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			out = append(out, s)
+		}
+		cur.Reset()
+		first = ""
+		hadBlock = false
+		routine = false
+	}
+	declaring := func() bool {
+		return len(blocks) > 0 && blocks[len(blocks)-1]
+	}
+
+	for i := 0; i < len(lexemes); i++ {
+		lx := lexemes[i]
+		if first == "" {
+			first = lx.word
+		}
+		if lx.text == ";" {
+			// Ends a forward declaration such as `PROCEDURE p;`.
+			routine = false
+		}
+		switch {
+		case lx.text == ";" && len(blocks) == 0:
+			if hadBlock {
+				cur.WriteString(";")
+			}
+			emit()
+			continue
+		case lx.word == "DECLARE" && len(blocks) == 0 && nextWord(lexemes, i) != "@":
+			blocks = append(blocks, true)
+			hadBlock = true
+		case (lx.word == "PROCEDURE" || lx.word == "FUNCTION" || lx.word == "PACKAGE") &&
+			(len(blocks) == 0 && first == "CREATE" || declaring()):
+			routine = true
+		case (lx.word == "IS" || lx.word == "AS") && routine && opensDeclarations(lexemes, i):
+			blocks = append(blocks, true)
+			hadBlock = true
+			routine = false
+		case lx.word == "BEGIN" && opensBlock(lexemes, i):
+			if declaring() {
+				blocks[len(blocks)-1] = false
+			} else {
+				blocks = append(blocks, false)
+			}
+			hadBlock = true
+		case lx.word == "CASE" && len(blocks) > 0:
+			blocks = append(blocks, false)
+		case lx.word == "END" && len(blocks) > 0:
+			switch next := nextWord(lexemes, i); next {
+			case "IF", "LOOP", "WHILE", "REPEAT", "FOR":
+				// END IF and friends close PL/SQL control flow, not a block.
+			case "CASE":
+				// END CASE closes a CASE statement; swallow the CASE so it
+				// does not open another level.
+				blocks = blocks[:len(blocks)-1]
+				cur.WriteString(lx.text)
+				for i++; lexemes[i].word != "CASE"; i++ {
+					cur.WriteString(lexemes[i].text)
+				}
+				lx = lexemes[i]
+			default:
+				blocks = blocks[:len(blocks)-1]
+			}
+		}
+		cur.WriteString(lx.text)
+	}
+	emit()
+	return out
+}
+
+// opensBlock reports whether the BEGIN at lexemes[i] starts a compound block
+// rather than a transaction. Transaction modes such as Postgres's
+// `BEGIN ISOLATION LEVEL ...`, `BEGIN READ ONLY` and `BEGIN NOT DEFERRABLE`
+// and SQL Server's `BEGIN DISTRIBUTED TRANSACTION` start a transaction too,
+// while MariaDB's `BEGIN NOT ATOMIC` opens a block.
+func opensBlock(lexemes []splitLexeme, i int) bool {
+	switch next := nextWord(lexemes, i); next {
+	case "", ";", "TRANSACTION", "TRAN", "WORK", "DEFERRED", "IMMEDIATE", "EXCLUSIVE",
+		"ISOLATION", "READ", "DEFERRABLE", "DISTRIBUTED":
+		return false
+	case "NOT":
+		return nextWord(lexemes, nextIndex(lexemes, i)) != "DEFERRABLE"
+	}
+	return true
+}
+
+// opensDeclarations reports whether the IS or AS at lexemes[i], following
+// CREATE PROCEDURE, FUNCTION or PACKAGE, starts a PL/SQL body. Postgres
+// bodies are string literals, Oracle call specs continue with LANGUAGE or
+// EXTERNAL, and SQL Server bodies without BEGIN start with a statement; none
+// of those has an END to close it.
+func opensDeclarations(lexemes []splitLexeme, i int) bool {
+	j := nextIndex(lexemes, i)
+	if j >= len(lexemes) || lexemes[j].word == "" {
+		return false
+	}
+	switch lexemes[j].word {
+	case "LANGUAGE", "EXTERNAL", "SELECT", "INSERT", "UPDATE", "DELETE", "MERGE", "WITH",
+		"SET", "RETURN", "EXEC", "EXECUTE", "IF", "WHILE", "DECLARE", "PRINT":
+		return false
+	case "BEGIN":
+		return opensBlock(lexemes, j)
+	}
+	return true
+}
+
+// splitLexeme is a word, a single punctuation byte or a whole scanSQL token.
+// word holds the upper-cased text for bare words and is empty otherwise;
+// skip marks whitespace and comments.
+type splitLexeme struct {
+	text string
+	word string
+	skip bool
+}
+
+func splitLexemes(sql string) []splitLexeme {
+	var lexemes []splitLexeme
+	for _, tok := range scanSQL(sql, "") {
+		switch tok.kind {
+		case tokenText:
+		case tokenSpace, tokenLineComment, tokenBlockComment:
+			lexemes = append(lexemes, splitLexeme{text: tok.text, skip: true})
+			continue
+		default:
+			lexemes = append(lexemes, splitLexeme{text: tok.text})
+			continue
+		}
+
+		text := tok.text
+		for len(text) > 0 {
+			n := 0
+			for n < len(text) && isWordByte(text[n]) {
+				n++
+			}
+			if n == 0 {
+				lexemes = append(lexemes, splitLexeme{text: text[:1]})
+				text = text[1:]
+				continue
+			}
+			lexemes = append(lexemes, splitLexeme{text: text[:n], word: strings.ToUpper(text[:n])})
+			text = text[n:]
+		}
+	}
+	return lexemes
+}
+
+// nextWord returns the upper-cased word or punctuation following lexemes[i],
+// skipping whitespace and comments, or "" at the end of input.
+func nextWord(lexemes []splitLexeme, i int) string {
+	j := nextIndex(lexemes, i)
+	if j >= len(lexemes) {
+		return ""
+	}
+	if lexemes[j].word != "" {
+		return lexemes[j].word
+	}
+	return lexemes[j].text
+}
+
+// nextIndex returns the index of the first lexeme after lexemes[i] that is
+// not whitespace or a comment, or an index past the end if there is none.
+func nextIndex(lexemes []splitLexeme, i int) int {
+	for i++; i < len(lexemes) && lexemes[i].skip; i++ {
+	}
+	return i
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
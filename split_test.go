@@ -0,0 +1,145 @@
+package sqlrender
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{
+			name: "simple",
+			in:   "CREATE TABLE a (id int);\n\nINSERT INTO a VALUES (1);  ;\n",
+			want: []string{"CREATE TABLE a (id int)", "INSERT INTO a VALUES (1)"},
+		},
+		{
+			name: "no trailing semicolon",
+			in:   "SELECT 1; SELECT 2",
+			want: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name: "literals and comments",
+			in:   "INSERT INTO t VALUES ('a;b', \"c;d\"); -- x; y\nSELECT 1 /* ; */;",
+			want: []string{"INSERT INTO t VALUES ('a;b', \"c;d\")", "-- x; y\nSELECT 1 /* ; */"},
+		},
+		{
+			name: "dollar quoted body",
+			in: "CREATE FUNCTION f() RETURNS trigger AS $fn$\nBEGIN\n  NEW.x := 1;\n  RETURN NEW;\nEND;\n$fn$ LANGUAGE plpgsql;\n" +
+				"SELECT $1;",
+			want: []string{
+				"CREATE FUNCTION f() RETURNS trigger AS $fn$\nBEGIN\n  NEW.x := 1;\n  RETURN NEW;\nEND;\n$fn$ LANGUAGE plpgsql",
+				"SELECT $1",
+			},
+		},
+		{
+			name: "begin end block",
+			in:   "CREATE TRIGGER t AFTER INSERT ON a BEGIN UPDATE b SET n = n + 1; DELETE FROM c; END; SELECT 1;",
+			want: []string{"CREATE TRIGGER t AFTER INSERT ON a BEGIN UPDATE b SET n = n + 1; DELETE FROM c; END;", "SELECT 1"},
+		},
+		{
+			name: "nested case and control flow",
+			in: "BEGIN\n  IF x THEN v := CASE WHEN y THEN 1 ELSE 2 END; END IF;\n" +
+				"  CASE v WHEN 1 THEN NULL; END CASE;\nEND;\nSELECT 2;",
+			want: []string{
+				"BEGIN\n  IF x THEN v := CASE WHEN y THEN 1 ELSE 2 END; END IF;\n  CASE v WHEN 1 THEN NULL; END CASE;\nEND;",
+				"SELECT 2",
+			},
+		},
+		{
+			name: "transactions",
+			in:   "BEGIN; UPDATE a SET x = 1; COMMIT; BEGIN TRANSACTION; SELECT CASE WHEN 1 THEN 2 END; END;",
+			want: []string{"BEGIN", "UPDATE a SET x = 1", "COMMIT", "BEGIN TRANSACTION", "SELECT CASE WHEN 1 THEN 2 END", "END"},
+		},
+		{
+			name: "isolation level",
+			in:   "BEGIN ISOLATION LEVEL SERIALIZABLE; UPDATE a SET x = 1; COMMIT;",
+			want: []string{"BEGIN ISOLATION LEVEL SERIALIZABLE", "UPDATE a SET x = 1", "COMMIT"},
+		},
+		{
+			name: "read only",
+			in:   "BEGIN READ ONLY; SELECT 1; COMMIT; BEGIN READ WRITE, NOT DEFERRABLE; SELECT 2; END;",
+			want: []string{"BEGIN READ ONLY", "SELECT 1", "COMMIT", "BEGIN READ WRITE, NOT DEFERRABLE", "SELECT 2", "END"},
+		},
+		{
+			name: "not deferrable",
+			in:   "BEGIN NOT DEFERRABLE; SELECT 1; COMMIT;",
+			want: []string{"BEGIN NOT DEFERRABLE", "SELECT 1", "COMMIT"},
+		},
+		{
+			name: "not atomic block",
+			in:   "BEGIN NOT ATOMIC SELECT 1; SELECT 2; END; SELECT 3;",
+			want: []string{"BEGIN NOT ATOMIC SELECT 1; SELECT 2; END;", "SELECT 3"},
+		},
+		{
+			name: "distributed transaction",
+			in:   "BEGIN DISTRIBUTED TRANSACTION; UPDATE a SET x = 1; COMMIT TRANSACTION;",
+			want: []string{"BEGIN DISTRIBUTED TRANSACTION", "UPDATE a SET x = 1", "COMMIT TRANSACTION"},
+		},
+		{
+			name: "try block",
+			in:   "BEGIN TRY SELECT 1; END TRY BEGIN CATCH SELECT 2; END CATCH; SELECT 3;",
+			want: []string{"BEGIN TRY SELECT 1; END TRY BEGIN CATCH SELECT 2; END CATCH;", "SELECT 3"},
+		},
+		{
+			name: "oracle declare block",
+			in:   "DECLARE v NUMBER; BEGIN NULL; END; SELECT 1 FROM dual;",
+			want: []string{"DECLARE v NUMBER; BEGIN NULL; END;", "SELECT 1 FROM dual"},
+		},
+		{
+			name: "oracle procedure",
+			in: "CREATE OR REPLACE PROCEDURE p IS v NUMBER; BEGIN v := 1; END p;\n" +
+				"CREATE FUNCTION f RETURN NUMBER AS BEGIN RETURN 1; END;",
+			want: []string{
+				"CREATE OR REPLACE PROCEDURE p IS v NUMBER; BEGIN v := 1; END p;",
+				"CREATE FUNCTION f RETURN NUMBER AS BEGIN RETURN 1; END;",
+			},
+		},
+		{
+			name: "oracle trigger",
+			in:   "CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW DECLARE n NUMBER; BEGIN n := 1; END; SELECT 1 FROM dual;",
+			want: []string{"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW DECLARE n NUMBER; BEGIN n := 1; END;", "SELECT 1 FROM dual"},
+		},
+		{
+			name: "oracle package",
+			in: "CREATE PACKAGE pkg AS PROCEDURE p; FUNCTION f RETURN NUMBER; END pkg;\n" +
+				"CREATE PACKAGE BODY pkg AS PROCEDURE p IS BEGIN NULL; END; FUNCTION f RETURN NUMBER IS n NUMBER; BEGIN RETURN n; END; END pkg;\n" +
+				"SELECT 1 FROM dual;",
+			want: []string{
+				"CREATE PACKAGE pkg AS PROCEDURE p; FUNCTION f RETURN NUMBER; END pkg;",
+				"CREATE PACKAGE BODY pkg AS PROCEDURE p IS BEGIN NULL; END; FUNCTION f RETURN NUMBER IS n NUMBER; BEGIN RETURN n; END; END pkg;",
+				"SELECT 1 FROM dual",
+			},
+		},
+		{
+			name: "declarations elsewhere",
+			in: "DECLARE @x int; SELECT @x; CREATE PROCEDURE p AS SELECT 1; " +
+				"CREATE FUNCTION g() RETURNS int AS 'SELECT 1' LANGUAGE sql; CREATE PROCEDURE q() BEGIN DECLARE v INT; SET v = 1; END; SELECT 2;",
+			want: []string{
+				"DECLARE @x int", "SELECT @x", "CREATE PROCEDURE p AS SELECT 1",
+				"CREATE FUNCTION g() RETURNS int AS 'SELECT 1' LANGUAGE sql", "CREATE PROCEDURE q() BEGIN DECLARE v INT; SET v = 1; END;", "SELECT 2",
+			},
+		},
+		{
+			name: "blank",
+			in:   " ; \n ",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := SplitStatements(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("split mismatch:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
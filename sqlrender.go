@@ -4,17 +4,27 @@ package sqlrender
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 )
 
 // Dialect describes how placeholders and identifiers should be rendered for a
-// specific database engine. The constants below cover the built-in dialects.
+// specific database engine. The constants below cover the built-in dialects;
+// others can be added with RegisterDialect.
 type Dialect string
 
 const (
@@ -29,8 +39,30 @@ const (
 // QueryArgs accumulates arguments to be bound into a SQL statement while
 // keeping track of the dialect-specific placeholder format.
 type QueryArgs struct {
-	args    []any
-	dialect Dialect
+	args             []any
+	dialect          Dialect
+	names            map[string][]int
+	inline           bool
+	transform        func(string) string
+	tableTransform   func(string) string
+	columnTransform  func(string) string
+	lengthUnit       LengthUnit
+	timings          map[string]time.Duration
+	template         string
+	sourcePath       string
+	maxArgs          int
+	upper            bool
+	quoteStyles      map[Dialect]QuoteStyle
+	schema           string
+	numericBools     bool
+	funcs            template.FuncMap
+	placeholderStyle PlaceholderStyle
+	legacyOracle     bool
+	emptyIn          EmptyInPolicy
+	nilPolicy        NilPolicy
+	mysqlRowAlias    string
+	warningsEnabled  bool
+	warnings         []string
 }
 
 // NewQueryArgs returns a binder that formats placeholders for the supplied
@@ -41,49 +73,346 @@ func NewQueryArgs(dialect Dialect) *QueryArgs {
 
 // Bind stores the provided value and returns a placeholder string. Slice and
 // array inputs expand into a comma-separated list wrapped in parentheses,
-// while nil values map to a single placeholder.
+// while nil values and driver.Valuer implementations map to a single
+// placeholder.
+//
+// Every call appends to the argument list immediately, so the returned
+// placeholder must always reach the output. Templates get this for free:
+// text/template only calls `bind` in branches it renders. Go helpers that
+// call Bind must do so only once they know the placeholder will be emitted;
+// binding eagerly and then discarding the string leaves an argument with no
+// placeholder. VerifyPlaceholders detects that mismatch.
+//
+// A sql.NamedArg is unwrapped: its Value is bound in its place and its Name
+// recorded as with BindNamedPositional, so it shows up in
+// Statement.NamedArgs. Placeholders stay positional on every dialect; only
+// the value reaches the driver.
 func (qa *QueryArgs) Bind(arg any) string {
+	if named, ok := arg.(sql.NamedArg); ok {
+		if named.Name == "" {
+			return qa.Bind(named.Value)
+		}
+		return qa.BindNamedPositional(named.Name, named.Value)
+	}
+
 	v := reflect.ValueOf(arg)
 
 	if !v.IsValid() {
-		qa.args = append(qa.args, nil)
-		return qa.placeholderFor(len(qa.args))
+		return qa.add(nil)
+	}
+
+	if !isList(v) {
+		return qa.add(byteArrayToSlice(v))
+	}
+
+	if v.Len() == 0 {
+		return qa.emptyList()
+	}
+	return qa.bindInList(v)
+}
+
+// BindList is Bind without the surrounding parentheses: a slice renders as
+// `$1, $2, $3`, leaving the wrapping to the template, e.g. inside a VALUES
+// row or an ARRAY[...] constructor. An empty list binds nothing and renders
+// an empty string; any other value binds as a single placeholder.
+func (qa *QueryArgs) BindList(arg any) string {
+	v := reflect.ValueOf(arg)
+	if !v.IsValid() || !isList(v) {
+		return qa.Bind(arg)
+	}
+	return qa.bindElems(v)
+}
+
+// bindInList binds the non-empty list value v as a parenthesized IN list,
+// warning when it is longer than Oracle accepts.
+func (qa *QueryArgs) bindInList(v reflect.Value) string {
+	if qa.dialect == DialectOracle && v.Len() > oracleMaxInList {
+		qa.warnf("bound a list of %d values; Oracle allows at most %d per IN list, use inChunked", v.Len(), oracleMaxInList)
+	}
+	return "(" + qa.bindElems(v) + ")"
+}
+
+// bindElems binds every element of the list value v and returns the
+// comma-separated placeholders without surrounding parentheses.
+func (qa *QueryArgs) bindElems(v reflect.Value) string {
+	placeholders := make([]string, v.Len())
+	for i := range placeholders {
+		placeholders[i] = qa.add(byteArrayToSlice(v.Index(i)))
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// byteArrayToSlice returns v's value, copying byte arrays such as [16]byte
+// into a []byte, which drivers accept and arrays they do not. Interface
+// values, such as the elements of a []any, are looked through.
+func byteArrayToSlice(v reflect.Value) any {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 && !v.Type().Implements(valuerType) {
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b
+	}
+	return v.Interface()
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// bindScalar binds v as one argument even when it is a slice, for values
+// that fill a single column, such as struct fields, where expanding a
+// []string into an IN list would break the statement.
+func (qa *QueryArgs) bindScalar(v reflect.Value) string {
+	if !v.IsValid() {
+		return qa.add(nil)
+	}
+	return qa.add(byteArrayToSlice(v))
+}
+
+// isList reports whether Bind expands v into one placeholder per element.
+// Types implementing driver.Valuer (such as pq.StringArray) are always bound
+// as a single argument, even when their underlying kind is a slice. So are
+// multi-dimensional slices such as [][]int, which are meant for array or JSON
+// columns rather than IN lists, and byte slices and arrays ([]byte, [16]byte),
+// which are binary values; slices of []byte still expand. Maps are never
+// lists and bind as a single argument.
+func isList(v reflect.Value) bool {
+	t := v.Type()
+	if t.Implements(valuerType) {
+		return false
 	}
 
-	switch v.Kind() {
+	switch t.Kind() {
 	case reflect.Slice, reflect.Array:
-		n := v.Len()
-		if n == 0 {
-			return "(NULL)"
+		return t.Elem().Kind() != reflect.Uint8 && !isNestedList(t.Elem())
+	default:
+		return false
+	}
+}
+
+// isNestedList reports whether the element type t is itself a list, which
+// makes its container multi-dimensional. Byte slices and Valuers count as
+// scalars.
+func isNestedList(t reflect.Type) bool {
+	if t.Implements(valuerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
+
+// BindNamedPositional binds the value exactly like Bind but also records the
+// supplied logical name against the positions it occupies. The placeholder
+// format is unchanged, so positional dialects still emit `?`; the recorded
+// names are meant for logging (e.g. `?[user_id]=42`).
+func (qa *QueryArgs) BindNamedPositional(name string, arg any) string {
+	if name == "" {
+		panic("sqlrender: empty positional name")
+	}
+
+	start := len(qa.args)
+	placeholder := qa.Bind(arg)
+
+	if qa.names == nil {
+		qa.names = make(map[string][]int)
+	}
+	for pos := start + 1; pos <= len(qa.args); pos++ {
+		qa.names[name] = append(qa.names[name], pos)
+	}
+
+	return placeholder
+}
+
+// PositionalNames returns a copy of the name to 1-based argument position
+// mapping recorded by BindNamedPositional.
+func (qa *QueryArgs) PositionalNames() map[string][]int {
+	names := make(map[string][]int, len(qa.names))
+	for name, positions := range qa.names {
+		names[name] = append([]int(nil), positions...)
+	}
+	return names
+}
+
+// BindNamed binds value under a logical name. On Oracle it emits the named
+// placeholder `:name` and stores a sql.NamedArg; repeating the name reuses the
+// same bind variable, so `:tenant` referenced three times costs one
+// argument. Rebinding a name with a different value panics, as does a list
+// value (a named bind variable holds one value). Drivers bind a statement
+// either by name or by position, so an Oracle statement using bindNamed
+// should bind every value through it.
+//
+// Other dialects, Oracle with a placeholder style override, and debug renders
+// have no reusable named placeholders and fall back to BindNamedPositional,
+// binding the value again at every use.
+func (qa *QueryArgs) BindNamed(name string, value any) string {
+	if !bindNamePattern.MatchString(name) {
+		panic(fmt.Sprintf("sqlrender: invalid bind name %q", name))
+	}
+	if qa.dialect != DialectOracle || qa.placeholderStyle != PlaceholderDefault || qa.inline {
+		return qa.BindNamedPositional(name, value)
+	}
+	if v := reflect.ValueOf(value); v.IsValid() {
+		if isList(v) {
+			panic(fmt.Sprintf("sqlrender: bindNamed %q expects a single value, got %T", name, value))
 		}
+		value = byteArrayToSlice(v)
+	}
 
-		placeholders := make([]string, n)
-		for i := 0; i < n; i++ {
-			val := v.Index(i).Interface()
-			qa.args = append(qa.args, val)
-			placeholders[i] = qa.placeholderFor(len(qa.args))
+	for _, arg := range qa.args {
+		if named, ok := arg.(sql.NamedArg); ok && named.Name == name {
+			if !reflect.DeepEqual(named.Value, qa.driverValue(value)) {
+				panic(fmt.Sprintf("sqlrender: bind name %q reused with a different value", name))
+			}
+			return ":" + name
 		}
-		return fmt.Sprintf("(%s)", strings.Join(placeholders, ", "))
-	default:
-		qa.args = append(qa.args, arg)
-		return qa.placeholderFor(len(qa.args))
 	}
+
+	qa.add(value)
+	last := len(qa.args) - 1
+	qa.args[last] = sql.Named(name, qa.args[last])
+	return ":" + name
+}
+
+var bindNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+func (qa *QueryArgs) add(arg any) string {
+	if arg == nil && qa.nilPolicy == NilAsError {
+		panic("sqlrender: cannot bind nil: the renderer's nil policy is NilAsError")
+	}
+	return qa.addNullable(arg)
+}
+
+// driverValue returns arg as it is stored for the driver: Go bools become 1
+// and 0 on Oracle with numeric bools enabled, everything else is unchanged.
+func (qa *QueryArgs) driverValue(arg any) any {
+	if b, ok := arg.(bool); ok && qa.numericBools && qa.dialect == DialectOracle {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	return arg
+}
+
+// addNullable is add without the nil policy check, for helpers where NULL is
+// the expected input.
+func (qa *QueryArgs) addNullable(arg any) string {
+	qa.checkArgLimit()
+	qa.warnNearArgLimit()
+	arg = qa.driverValue(arg)
+	qa.args = append(qa.args, arg)
+	if qa.inline {
+		return formatLiteral(qa.dialect, arg)
+	}
+	return qa.placeholderFor(len(qa.args))
 }
 
 var identifierPattern = regexp.MustCompile(`^[A-Za-z0-9._]+$`)
 
+// maxIdentifierParts caps dotted names at schema.table.column.
+const maxIdentifierParts = 3
+
+// validIdentifier reports whether s is made of 1 to maxIdentifierParts
+// non-empty, dot-separated parts using only the characters identifierPattern
+// allows. Leading, trailing and doubled dots would quote as empty parts.
+func validIdentifier(s string) bool {
+	if !identifierPattern.MatchString(s) {
+		return false
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > maxIdentifierParts {
+		return false
+	}
+	for _, part := range parts {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
+
 // Identifier quotes the supplied identifier (optionally schema-qualified) for
 // the current dialect. Only alphanumeric characters, underscores, and periods
-// are permitted; invalid identifiers trigger a panic to surface template issues
-// early.
+// are permitted, with at most three non-empty dot-separated parts; invalid
+// identifiers trigger a panic to surface template issues early.
 func (qa *QueryArgs) Identifier(name any) string {
+	return qa.identifierAny(name, qa.transform)
+}
+
+// TableIdentifier quotes a table name like Identifier, additionally applying
+// the renderer's table transformer (after the general one).
+func (qa *QueryArgs) TableIdentifier(name any) string {
+	return qa.identifierAny(name, qa.transform, qa.tableTransform)
+}
+
+// ColumnIdentifier quotes a column name like Identifier, additionally
+// applying the renderer's column transformer (after the general one).
+func (qa *QueryArgs) ColumnIdentifier(name any) string {
+	return qa.identifierAny(name, qa.transform, qa.columnTransform)
+}
+
+// Table quotes a table name like TableIdentifier, first qualifying bare
+// (non-dotted) names with the renderer's default schema. Already qualified
+// names are left alone, so one template can serve several schemas.
+func (qa *QueryArgs) Table(name any) string {
+	quoted := qa.TableIdentifier(name)
+	s, _ := name.(string)
+	if quoted == "" || qa.schema == "" || strings.Contains(s, ".") {
+		return quoted
+	}
+
+	schema, err := qa.transformIdentifier(qa.schema)
+	if err != nil {
+		panic(err.Error())
+	}
+	return schema + "." + quoted
+}
+
+func (qa *QueryArgs) identifierAny(name any, transforms ...func(string) string) string {
 	s, ok := name.(string)
 	if !ok || s == "" {
 		return ""
 	}
 
-	if !identifierPattern.MatchString(s) {
-		panic(fmt.Sprintf("sqlrender: invalid identifier %q", s))
+	quoted, err := qa.transformIdentifier(s, transforms...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return quoted
+}
+
+// identifier validates and quotes s, reporting invalid input as an error so
+// helpers can decide whether to panic or propagate it.
+func (qa *QueryArgs) identifier(s string) (string, error) {
+	return qa.transformIdentifier(s, qa.transform)
+}
+
+// transformIdentifier validates s, applies the non-nil transforms in order
+// (validating each result) and quotes the outcome.
+func (qa *QueryArgs) transformIdentifier(s string, transforms ...func(string) string) (string, error) {
+	if quoted, ok, err := qa.preQuotedIdentifier(s); ok {
+		return quoted, err
+	}
+	if !validIdentifier(s) {
+		return "", fmt.Errorf("sqlrender: invalid identifier %q", s)
+	}
+
+	for _, transform := range transforms {
+		if transform == nil {
+			continue
+		}
+		transformed := transform(s)
+		if !validIdentifier(transformed) {
+			return "", fmt.Errorf("sqlrender: identifier transformer produced invalid identifier %q from %q", transformed, s)
+		}
+		s = transformed
+	}
+	if qa.upper {
+		s = strings.ToUpper(s)
 	}
 
 	parts := strings.Split(s, ".")
@@ -91,21 +420,69 @@ func (qa *QueryArgs) Identifier(name any) string {
 		parts[i] = qa.quoteIdentifier(part)
 	}
 
-	return strings.Join(parts, ".")
+	return strings.Join(parts, "."), nil
+}
+
+var identifierPartPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// preQuotedIdentifier handles names in which at least one dot-separated part
+// is already wrapped in the dialect's quote characters, such as
+// `"public"."users"` on Postgres. Quoted parts are kept as written once their
+// content passes the usual character rules, bare parts are quoted, and the
+// name is not transformed or case-folded: hand-quoting takes precedence over
+// renderer settings. ok is false when no part is quoted.
+func (qa *QueryArgs) preQuotedIdentifier(s string) (quoted string, ok bool, err error) {
+	left, right, found := strings.Cut(qa.quoteIdentifier("x"), "x")
+	if !found || left == "" || !strings.Contains(s, left) {
+		return "", false, nil
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > maxIdentifierParts {
+		return "", true, fmt.Errorf("sqlrender: invalid identifier %q", s)
+	}
+	for i, part := range parts {
+		inner := part
+		isQuoted := len(part) > len(left)+len(right) && strings.HasPrefix(part, left) && strings.HasSuffix(part, right)
+		if isQuoted {
+			inner = part[len(left) : len(part)-len(right)]
+		}
+		if !identifierPartPattern.MatchString(inner) {
+			return "", true, fmt.Errorf("sqlrender: invalid identifier %q", s)
+		}
+		if !isQuoted {
+			parts[i] = qa.quoteIdentifier(part)
+		}
+	}
+	return strings.Join(parts, "."), true, nil
 }
 
 func (qa *QueryArgs) quoteIdentifier(id string) string {
+	if quoted, ok := qa.quoteStyles[qa.dialect].quote(id); ok {
+		return quoted
+	}
+	if spec, ok := lookupDialect(qa.dialect); ok && spec.Quote != nil {
+		return spec.Quote(id)
+	}
+
 	switch qa.dialect {
-	case DialectPostgres, DialectOracle:
+	case DialectPostgres, DialectOracle, DialectSnowflake, DialectSQLite:
 		return `"` + id + `"`
 	case DialectSQLServer:
 		return `[` + id + `]`
 	default:
-		return "`" + id + "`" // MySQL, SQLite, Snowflake
+		return "`" + id + "`" // MySQL
 	}
 }
 
 func (qa *QueryArgs) placeholderFor(n int) string {
+	if ph, ok := qa.placeholderStyle.placeholder(n); ok {
+		return ph
+	}
+	if spec, ok := lookupDialect(qa.dialect); ok && spec.Placeholder != nil {
+		return spec.Placeholder(n)
+	}
+
 	switch qa.dialect {
 	case DialectPostgres:
 		return fmt.Sprintf("$%d", n)
@@ -121,9 +498,34 @@ func (qa *QueryArgs) placeholderFor(n int) string {
 // Renderer turns Go text templates into SQL statements while collecting the
 // bound arguments.
 type Renderer struct {
-	searchPaths    []string
-	defaultDialect Dialect
-	customFuncs    template.FuncMap
+	searchPaths      []string
+	recursivePaths   []string
+	defaultDialect   Dialect
+	defaultExtension string
+	customFuncs      template.FuncMap
+	transform        func(string) string
+	tableTransform   func(string) string
+	columnTransform  func(string) string
+	lengthUnit       LengthUnit
+	profiling        bool
+	minify           bool
+	trimBlocks       bool
+	strictInterp     bool
+	hook             func(RenderEvent)
+	maxArgs          int
+	upper            bool
+	quoteStyles      map[Dialect]QuoteStyle
+	schema           string
+	numericBools     bool
+	strictArgs       bool
+	pool             *sync.Pool
+	placeholderStyle PlaceholderStyle
+	legacyOracle     bool
+	maxOutput        int
+	emptyIn          EmptyInPolicy
+	nilPolicy        NilPolicy
+	mysqlRowAlias    string
+	warningsEnabled  bool
 }
 
 // NewRenderer returns a Renderer that defaults to the provided dialect when no
@@ -135,6 +537,27 @@ func NewRenderer(defaultDialect Dialect) *Renderer {
 	}
 }
 
+// Clone returns an independent copy of the renderer: search paths, custom
+// funcs, quote styles and every scalar setting are copied, so configuring the
+// clone (say, a per-request dialect or schema) never affects r and needs no
+// locking. Functions such as transformers and the render hook are shared by
+// reference. A pooling renderer's clone gets its own pool.
+func (r *Renderer) Clone() *Renderer {
+	c := *r
+	c.searchPaths = slices.Clone(r.searchPaths)
+	c.recursivePaths = slices.Clone(r.recursivePaths)
+	c.customFuncs = maps.Clone(r.customFuncs)
+	if c.customFuncs == nil {
+		c.customFuncs = make(template.FuncMap)
+	}
+	c.quoteStyles = maps.Clone(r.quoteStyles)
+	if r.pool != nil {
+		c.pool = nil
+		c.SetArgsPooling(true)
+	}
+	return &c
+}
+
 // SetDefaultDialect updates the renderer's default dialect and returns the
 // renderer to allow fluent configuration.
 func (r *Renderer) SetDefaultDialect(d Dialect) *Renderer {
@@ -149,12 +572,141 @@ func (r *Renderer) AddSearchPath(path string) *Renderer {
 	return r
 }
 
+// AddSearchPathRecursive appends a directory whose whole subtree is searched
+// for template files, after every non-recursive search path. A name matches
+// any file whose path relative to root ends with it, so "find_user.sql"
+// finds "users/find_user.sql"; when several subdirectories hold a match the
+// lookup fails and lists the candidates instead of picking one.
+func (r *Renderer) AddSearchPathRecursive(root string) *Renderer {
+	r.recursivePaths = append(r.recursivePaths, root)
+	return r
+}
+
 // SetSearchPaths replaces the search path list with the provided directories.
 func (r *Renderer) SetSearchPaths(paths []string) *Renderer {
 	r.searchPaths = paths
 	return r
 }
 
+// SetDefaultExtension sets an extension (such as ".sql") that is appended to a
+// template name when the name cannot be found as-is, so templates can be
+// referenced by logical name. It is empty by default.
+func (r *Renderer) SetDefaultExtension(ext string) *Renderer {
+	r.defaultExtension = ext
+	return r
+}
+
+// SetIdentifierTransformer installs a function applied to every identifier
+// after validation and before quoting, e.g. to add a table prefix or map
+// logical names to physical ones. The transformer receives the full
+// (possibly dotted) name and its result is validated again. Passing nil
+// removes the transformer.
+func (r *Renderer) SetIdentifierTransformer(fn func(name string) string) *Renderer {
+	r.transform = fn
+	return r
+}
+
+// SetTableTransformer installs a transformer used only by `tableIdentifier`,
+// applied after the general identifier transformer. Passing nil removes it.
+func (r *Renderer) SetTableTransformer(fn func(name string) string) *Renderer {
+	r.tableTransform = fn
+	return r
+}
+
+// SetColumnTransformer installs a transformer used only by
+// `columnIdentifier`, applied after the general identifier transformer.
+// Passing nil removes it.
+func (r *Renderer) SetColumnTransformer(fn func(name string) string) *Renderer {
+	r.columnTransform = fn
+	return r
+}
+
+// SetUppercaseIdentifiers folds every identifier to uppercase before it is
+// quoted. Snowflake and Oracle fold unquoted names to uppercase but keep the
+// case of quoted ones, so without folding a quoted "users" refers to a
+// different object than an unquoted users. Folding happens after any
+// identifier transformers. It is off by default.
+func (r *Renderer) SetUppercaseIdentifiers(on bool) *Renderer {
+	r.upper = on
+	return r
+}
+
+// SetMinify controls whether rendered SQL is compacted by stripping comments
+// and collapsing whitespace runs to single spaces. String literals, quoted
+// identifiers and dollar-quoted bodies are left untouched. It is off by
+// default.
+func (r *Renderer) SetMinify(on bool) *Renderer {
+	r.minify = on
+	return r
+}
+
+// SetTrimBlocks controls whether blank lines left behind by control actions
+// such as {{ if }} and {{ range }} are removed from rendered SQL, along with
+// trailing whitespace on each line, so templates stay readable without
+// {{- and -}} everywhere. Indentation of the remaining lines is kept, and
+// literals, quoted identifiers and comments are left untouched. It is off by
+// default; SetMinify supersedes it.
+func (r *Renderer) SetTrimBlocks(on bool) *Renderer {
+	r.trimBlocks = on
+	return r
+}
+
+// SetDefaultSchema sets the schema that the `table` helper prepends to bare
+// table names, e.g. a tenant schema chosen per request. An empty name (the
+// default) disables qualification.
+func (r *Renderer) SetDefaultSchema(name string) *Renderer {
+	r.schema = name
+	return r
+}
+
+// SetOracleNumericBools makes bind pass Go bools to Oracle as 1 and 0, for
+// drivers that reject bool arguments against NUMBER(1) columns. Other
+// dialects are unaffected. It is off by default.
+func (r *Renderer) SetOracleNumericBools(on bool) *Renderer {
+	r.numericBools = on
+	return r
+}
+
+// SetLegacyOracle targets Oracle releases before 12c, which lack
+// OFFSET/FETCH: limit fails and paginate (and Builder) fall back to a
+// ROW_NUMBER() wrapper with the row range bound as arguments. It is off by
+// default and only affects the Oracle dialect.
+func (r *Renderer) SetLegacyOracle(on bool) *Renderer {
+	r.legacyOracle = on
+	return r
+}
+
+// SetStrictArgs makes every render check its output with VerifyPlaceholders
+// and fail when the placeholders and the bound arguments disagree, instead of
+// leaving the driver to report a cryptic bind error. Debug renders are not
+// checked. Strict renders buffer their output, including FromStringTo. It is
+// off by default.
+func (r *Renderer) SetStrictArgs(on bool) *Renderer {
+	r.strictArgs = on
+	return r
+}
+
+// SetEmptyInPolicy selects what `bind` and `in` produce for an empty list; see
+// EmptyInPolicy. The default, EmptyInNull, keeps `bind` emitting `(NULL)`.
+func (r *Renderer) SetEmptyInPolicy(policy EmptyInPolicy) *Renderer {
+	r.emptyIn = policy
+	return r
+}
+
+// SetNilPolicy selects whether binding an untyped nil is allowed (NilAsNull,
+// the default) or fails the render (NilAsError).
+func (r *Renderer) SetNilPolicy(policy NilPolicy) *Renderer {
+	r.nilPolicy = policy
+	return r
+}
+
+// SetLengthUnit selects whether `bindVarchar` measures strings in runes
+// (the default) or bytes.
+func (r *Renderer) SetLengthUnit(unit LengthUnit) *Renderer {
+	r.lengthUnit = unit
+	return r
+}
+
 // AddFunc registers a single custom template function that will be available to
 // all rendered templates.
 func (r *Renderer) AddFunc(name string, fn any) *Renderer {
@@ -179,81 +731,538 @@ func (r *Renderer) AddFuncs(funcs template.FuncMap) *Renderer {
 
 // FromStringWithDialect renders the provided template string using the supplied
 // dialect. It exposes the `bind` and `identifier` helper functions inside the
-// template and returns both the rendered SQL and the collected arguments.
+// template and returns both the rendered SQL and the collected arguments. data
+// becomes the template's dot and may be a map, a struct or a pointer to one; a
+// nil value is replaced by an empty map.
 func (r *Renderer) FromStringWithDialect(
 	s string,
-	data map[string]any,
+	data any,
+	dialect Dialect,
+) (string, []any, error) {
+	qa := r.pooledQueryArgs(dialect)
+	defer r.releaseQueryArgs(qa)
+	sql, err := r.render(s, data, qa)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, r.resultArgs(qa), nil
+}
+
+// FromStringWithPositionalNames renders the template like FromStringWithDialect
+// and additionally returns the name to position mapping recorded by
+// `bindNamedPositional` calls inside the template.
+func (r *Renderer) FromStringWithPositionalNames(
+	s string,
+	data any,
+	dialect Dialect,
+) (string, []any, map[string][]int, error) {
+	qa := r.pooledQueryArgs(dialect)
+	defer r.releaseQueryArgs(qa)
+	sql, err := r.render(s, data, qa)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return sql, r.resultArgs(qa), qa.PositionalNames(), nil
+}
+
+// FromStringWithArgs renders like FromStringWithDialect with the positional
+// args as the template's dot, so simple queries need no map:
+//
+//	r.FromStringWithArgs(`WHERE id = {{ bind (index . 0) }}`, DialectPostgres, id)
+//
+// Indexing past the last argument fails the render.
+func (r *Renderer) FromStringWithArgs(s string, dialect Dialect, args ...any) (string, []any, error) {
+	if args == nil {
+		args = []any{}
+	}
+	return r.FromStringWithDialect(s, args, dialect)
+}
+
+// FromStringWithFuncs renders like FromStringWithDialect with extra template
+// functions layered on top of the builtins and the renderer's custom
+// functions for this call only; on a name clash the extra function wins. It
+// lets a shared renderer take one-off helpers, or override `identifier` and
+// friends, without mutating state other goroutines may be using.
+func (r *Renderer) FromStringWithFuncs(
+	s string,
+	data any,
+	dialect Dialect,
+	extra template.FuncMap,
+) (string, []any, error) {
+	qa := r.pooledQueryArgs(dialect)
+	defer r.releaseQueryArgs(qa)
+	qa.funcs = extra
+	sql, err := r.render(s, data, qa)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, r.resultArgs(qa), nil
+}
+
+// FromStringTo renders the template directly into w instead of buffering the
+// SQL in memory, returning only the collected arguments. It suits very large
+// generated statements that are streamed to a file or connection. If rendering
+// fails, w may already have received partial output.
+func (r *Renderer) FromStringTo(w io.Writer, s string, data any, dialect Dialect) ([]any, error) {
+	qa := r.pooledQueryArgs(dialect)
+	defer r.releaseQueryArgs(qa)
+	start := time.Now()
+	err := r.renderTo(context.Background(), w, s, data, qa)
+	r.observe(qa, "", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return r.resultArgs(qa), nil
+}
+
+// newQueryArgs returns a binder for dialect configured from the renderer.
+func (r *Renderer) newQueryArgs(dialect Dialect) *QueryArgs {
+	qa := NewQueryArgs(dialect)
+	r.configureQueryArgs(qa, dialect)
+	return qa
+}
+
+// configureQueryArgs applies the renderer's settings to qa.
+func (r *Renderer) configureQueryArgs(qa *QueryArgs, dialect Dialect) {
+	qa.dialect = dialect
+	qa.transform = r.transform
+	qa.tableTransform = r.tableTransform
+	qa.columnTransform = r.columnTransform
+	qa.lengthUnit = r.lengthUnit
+	qa.maxArgs = r.maxArgs
+	qa.upper = r.upper
+	qa.quoteStyles = r.quoteStyles
+	qa.schema = r.schema
+	qa.numericBools = r.numericBools
+	qa.placeholderStyle = r.placeholderStyle
+	qa.legacyOracle = r.legacyOracle
+	qa.emptyIn = r.emptyIn
+	qa.nilPolicy = r.nilPolicy
+	qa.mysqlRowAlias = r.mysqlRowAlias
+	qa.warningsEnabled = r.warningsEnabled
+}
+
+// FromStringContext renders the template like FromStringWithDialect but aborts
+// with ctx.Err() if the context is done before parsing or before execution.
+// The context is also available to templates through `ctxValue`, which
+// returns ctx.Value(key).
+func (r *Renderer) FromStringContext(
+	ctx context.Context,
+	s string,
+	data any,
+	dialect Dialect,
+) (string, []any, error) {
+	qa := r.pooledQueryArgs(dialect)
+	defer r.releaseQueryArgs(qa)
+	sql, err := r.renderContext(ctx, s, data, qa)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, r.resultArgs(qa), nil
+}
+
+// FromTemplateContext is the template-file equivalent of FromStringContext.
+func (r *Renderer) FromTemplateContext(
+	ctx context.Context,
+	name string,
+	data any,
 	dialect Dialect,
 ) (string, []any, error) {
+	qa := r.pooledQueryArgs(dialect)
+	defer r.releaseQueryArgs(qa)
+	sql, err := r.renderTemplate(ctx, name, data, qa)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, r.resultArgs(qa), nil
+}
+
+func (r *Renderer) render(s string, data any, qa *QueryArgs) (string, error) {
+	return r.renderContext(context.Background(), s, data, qa)
+}
+
+func (r *Renderer) renderContext(ctx context.Context, s string, data any, qa *QueryArgs) (string, error) {
+	start := time.Now()
+	var buf bytes.Buffer
+	if err := r.renderTo(ctx, &buf, s, data, qa); err != nil {
+		r.observe(qa, "", start, err)
+		return "", err
+	}
+	r.observe(qa, buf.String(), start, nil)
+	return buf.String(), nil
+}
+
+// renderTemplate loads the named template and renders it into qa, recording
+// the name for the render hook and the resolved path for the Statement.
+func (r *Renderer) renderTemplate(ctx context.Context, name string, data any, qa *QueryArgs) (string, error) {
+	qa.template = name
+	path, content, err := r.readTemplate(name)
+	if err != nil {
+		r.observe(qa, "", time.Now(), err)
+		return "", err
+	}
+	qa.sourcePath = path
+	return r.renderContext(ctx, content, data, qa)
+}
+
+func (r *Renderer) renderTo(ctx context.Context, w io.Writer, s string, data any, qa *QueryArgs) error {
 	if data == nil {
 		data = map[string]any{}
 	}
 
-	qa := NewQueryArgs(dialect)
-	funcMap := template.FuncMap{
-		"bind":       qa.Bind,
-		"identifier": qa.Identifier,
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tmpl, err := r.parse(ctx, "sql", s, qa)
+	if err != nil {
+		if qa.sourcePath != "" {
+			return fmt.Errorf("sqlrender: failed to parse %q: %w", qa.sourcePath, err)
+		}
+		return err
 	}
 
-	for name, fn := range r.customFuncs {
-		funcMap[name] = fn
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	tmpl, err := template.New("sql").Funcs(funcMap).Parse(s)
+	return r.execute(w, tmpl, data, qa)
+}
+
+// parse parses s as a template named name with the functions bound to qa
+// and, with strict interpolation on, rejects it if any action writes template
+// data without a helper.
+func (r *Renderer) parse(ctx context.Context, name, s string, qa *QueryArgs) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(r.funcMap(ctx, qa)).Parse(s)
 	if err != nil {
-		return "", nil, err
+		return nil, err
+	}
+	if r.strictInterp {
+		custom := func(fn string) bool {
+			_, ok := r.customFuncs[fn]
+			_, extra := qa.funcs[fn]
+			return ok || extra
+		}
+		if err := checkInterpolation(tmpl, custom); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// execute runs a parsed template whose functions are bound to qa, applying
+// minification and strict argument checks.
+func (r *Renderer) execute(w io.Writer, tmpl *template.Template, data any, qa *QueryArgs) error {
+	strict := r.strictArgs && !qa.inline
+	if !r.minify && !r.trimBlocks && !strict {
+		return tmpl.Execute(r.limitOutput(w), data)
 	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", nil, err
+	if err := tmpl.Execute(r.limitOutput(&buf), data); err != nil {
+		return err
+	}
+	out := buf.String()
+	if r.minify {
+		out = minifySQL(out, qa.dialect)
+	} else if r.trimBlocks {
+		out = trimBlocksSQL(out, qa.dialect)
+	}
+	if strict {
+		if err := qa.verifyPlaceholders(out, qa.args); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+// funcMap returns the builtin helpers bound to qa plus the renderer's custom
+// functions.
+func (r *Renderer) funcMap(ctx context.Context, qa *QueryArgs) template.FuncMap {
+	funcMap := template.FuncMap{
+		"bind":                qa.Bind,
+		"bindList":            qa.BindList,
+		"bindNamedPositional": qa.BindNamedPositional,
+		"bindNamed":           qa.BindNamed,
+		"bindIf":              qa.BindIf,
+		"bindOrDefault":       qa.BindOrDefault,
+		"bindVarchar":         qa.BindVarchar,
+		"bindCast":            qa.BindCast,
+		"bindCastSlice":       qa.BindCastSlice,
+		"bindJSON":            qa.BindJSON,
+		"stringLit":           qa.StringLiteral,
+		"identifier":          qa.Identifier,
+		"tableIdentifier":     qa.TableIdentifier,
+		"table":               qa.Table,
+		"columnIdentifier":    qa.ColumnIdentifier,
+		"columns":             qa.Columns,
+		"identifierList":      qa.IdentifierList,
+		"identifierWhitelist": qa.IdentifierWhitelist,
+		"csv":                 qa.JoinIdentifiers,
+		"agg":                 qa.Agg,
+		"orderBy":             qa.OrderBy,
+		"explain":             qa.Explain,
+		"onConflict":          qa.OnConflict,
+		"upsertWhereChanged":  qa.UpsertWhereChanged,
+		"returning":           qa.Returning,
+		"top":                 qa.Top,
+		"limit":               qa.Limit,
+		"paginate":            qa.Paginate,
+		"set":                 qa.Set,
+		"setChanged":          qa.SetChanged,
+		"insertStruct":        qa.InsertStruct,
+		"insert":              qa.Insert,
+		"valuesAs":            qa.ValuesAs,
+		"valuesFields":        qa.ValuesFields,
+		"union":               qa.Union,
+		"cte":                 NewCTE,
+		"withCTE":             qa.With,
+		"withRecursiveCTE":    qa.WithRecursive,
+		"distinctOn":          qa.DistinctOn,
+		"where":               qa.Where,
+		"sqlAnd":              qa.And,
+		"sqlOr":               qa.Or,
+		"sqlNot":              qa.Not,
+		"orWhere":             qa.OrWhere,
+		"between":             qa.Between,
+		"in":                  qa.In,
+		"notIn":               qa.NotIn,
+		"like":                qa.Like,
+		"inChunked":           qa.InChunked,
+		"boolEq":              qa.BoolEq,
+		"boolLit":             qa.Bool,
+		"eqNullable":          qa.EqNullable,
+		"eqAll":               qa.EqAll,
+		"neNullable":          qa.NeNullable,
+		"currentDate":         qa.CurrentDate,
+		"ctxValue":            ctx.Value,
+		"dialect":             func() Dialect { return qa.dialect },
+		"default":             defaultValue,
+		"coalesceVal":         coalesceValue,
 	}
 
-	return buf.String(), qa.args, nil
+	for _, funcs := range []template.FuncMap{r.customFuncs, qa.funcs} {
+		for name, fn := range funcs {
+			if r.profiling {
+				fn = qa.timed(name, fn)
+			}
+			funcMap[name] = fn
+		}
+	}
+
+	return funcMap
 }
 
 // FromString renders a template string using the renderer's default dialect.
-func (r *Renderer) FromString(s string, data map[string]any) (string, []any, error) {
+func (r *Renderer) FromString(s string, data any) (string, []any, error) {
 	return r.FromStringWithDialect(s, data, r.defaultDialect)
 }
 
+// MustFromString is like FromString but panics if rendering fails. It is meant
+// for package-level queries rendered at startup, where a template error is a
+// programming bug, mirroring template.Must.
+func (r *Renderer) MustFromString(s string, data any) (string, []any) {
+	sql, args, err := r.FromString(s, data)
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
 // FromTemplateWithDialect loads the named template file, applying the search
 // paths when necessary, and renders it using the supplied dialect.
 func (r *Renderer) FromTemplateWithDialect(
 	name string,
-	data map[string]any,
+	data any,
 	dialect Dialect,
 ) (string, []any, error) {
-	path, err := r.findTemplateFile(name)
+	qa := r.pooledQueryArgs(dialect)
+	defer r.releaseQueryArgs(qa)
+	sql, err := r.renderTemplate(context.Background(), name, data, qa)
 	if err != nil {
 		return "", nil, err
 	}
+	return sql, r.resultArgs(qa), nil
+}
+
+// FromTemplate renders the named template file using the renderer's default
+// dialect.
+func (r *Renderer) FromTemplate(name string, data any) (string, []any, error) {
+	return r.FromTemplateWithDialect(name, data, r.defaultDialect)
+}
+
+// MustFromTemplate is like FromTemplate but panics if loading or rendering
+// fails.
+func (r *Renderer) MustFromTemplate(name string, data any) (string, []any) {
+	sql, args, err := r.FromTemplate(name, data)
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// FromStringDebug renders the template and inlines every bound argument as a
+// dialect-specific SQL literal instead of a placeholder. Slices expand exactly
+// as they would for the driver, so the output mirrors the statement that would
+// actually run.
+//
+// The result is intended for logging and troubleshooting only: literal
+// formatting is best effort and must never be treated as a safe substitute
+// for parameter binding. Do not execute the returned SQL.
+func (r *Renderer) FromStringDebug(s string, data any, dialect Dialect) (string, error) {
+	qa := r.pooledQueryArgs(dialect)
+	defer r.releaseQueryArgs(qa)
+	qa.inline = true
+	return r.render(s, data, qa)
+}
+
+// FromTemplateDebug is the template-file equivalent of FromStringDebug. The
+// same warning applies: the output is for humans, not for execution.
+func (r *Renderer) FromTemplateDebug(name string, data any, dialect Dialect) (string, error) {
+	qa := r.pooledQueryArgs(dialect)
+	defer r.releaseQueryArgs(qa)
+	qa.inline = true
+	return r.renderTemplate(context.Background(), name, data, qa)
+}
+
+// readTemplate resolves name to an absolute path and returns the path with the
+// file's content.
+func (r *Renderer) readTemplate(name string) (string, string, error) {
+	path, err := r.ResolveTemplate(name)
+	if err != nil {
+		return "", "", err
+	}
 
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return "", nil, fmt.Errorf("sqlrender: failed to read %q: %w", path, err)
+		return "", "", fmt.Errorf("sqlrender: failed to read %q: %w", path, err)
 	}
 
-	return r.FromStringWithDialect(string(content), data, dialect)
+	return path, string(content), nil
 }
 
-// FromTemplate renders the named template file using the renderer's default
-// dialect.
-func (r *Renderer) FromTemplate(name string, data map[string]any) (string, []any, error) {
-	return r.FromTemplateWithDialect(name, data, r.defaultDialect)
+// ResolveTemplate returns the absolute path of the file FromTemplate and
+// friends would load for name, without reading or rendering it. Use it with
+// TemplateSearchOrder to find out which search path satisfied a name, e.g.
+// when a stale copy shadows the intended template.
+func (r *Renderer) ResolveTemplate(name string) (string, error) {
+	path, err := r.findTemplateFile(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(path)
 }
 
-func (r *Renderer) findTemplateFile(name string) (string, error) {
-	if _, err := os.Stat(name); err == nil {
-		return name, nil
+// TemplateSearchOrder lists, in order, the locations checked when resolving
+// name: the name itself, then each search path, repeated with the default
+// extension appended when it is missing. Recursive search paths come last
+// and are shown as `root/**/name`; a name found more than once under one of
+// them is an error rather than a match.
+func (r *Renderer) TemplateSearchOrder(name string) []string {
+	var order []string
+	candidates := r.templateCandidates(name)
+	for _, candidate := range candidates {
+		order = append(order, r.directPaths(candidate)...)
+	}
+	for _, candidate := range candidates {
+		for _, root := range r.recursivePaths {
+			order = append(order, filepath.Join(root, "**", candidate))
+		}
 	}
+	return order
+}
+
+// templateCandidates returns name and, when it lacks the default extension,
+// name with the extension appended.
+func (r *Renderer) templateCandidates(name string) []string {
+	candidates := []string{name}
+	if r.defaultExtension != "" && !strings.HasSuffix(name, r.defaultExtension) {
+		candidates = append(candidates, name+r.defaultExtension)
+	}
+	return candidates
+}
 
+// directPaths returns candidate followed by candidate joined to every plain
+// search path.
+func (r *Renderer) directPaths(candidate string) []string {
+	paths := make([]string, 0, 1+len(r.searchPaths))
+	paths = append(paths, candidate)
 	for _, dir := range r.searchPaths {
-		full := filepath.Join(dir, name)
-		if _, err := os.Stat(full); err == nil {
-			return full, nil
+		paths = append(paths, filepath.Join(dir, candidate))
+	}
+	return paths
+}
+
+func (r *Renderer) findTemplateFile(name string) (string, error) {
+	candidates := r.templateCandidates(name)
+
+	for _, candidate := range candidates {
+		for _, path := range r.directPaths(candidate) {
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
 		}
 	}
 
+	for _, candidate := range candidates {
+		for _, root := range r.recursivePaths {
+			matches, err := findInTree(root, candidate)
+			if err != nil {
+				return "", err
+			}
+			switch len(matches) {
+			case 0:
+				continue
+			case 1:
+				return matches[0], nil
+			default:
+				return "", fmt.Errorf("sqlrender: template %q is ambiguous under %q: %q", name, root, matches)
+			}
+		}
+	}
+
+	if len(candidates) > 1 {
+		return "", fmt.Errorf("sqlrender: template %q not found in search paths: %v (tried %q)", name, r.searchPaths, candidates)
+	}
 	return "", fmt.Errorf("sqlrender: template %q not found in search paths: %v", name, r.searchPaths)
 }
+
+// findInTree walks root and returns every regular file whose path relative to
+// root is name or ends with a path separator followed by name.
+func findInTree(root, name string) ([]string, error) {
+	name = filepath.Clean(name)
+	var matches []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == name || strings.HasSuffix(rel, string(filepath.Separator)+name) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("sqlrender: failed to search %q: %w", root, err)
+	}
+	return matches, nil
+}
+
+// sortedKeys returns the keys of m in ascending order. Every helper that turns
+// a map into SQL must iterate through it so output is byte-identical across
+// renders.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
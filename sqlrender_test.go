@@ -1,6 +1,11 @@
 package sqlrender
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -68,6 +73,72 @@ func TestQueryArgsBindSliceAndArray(t *testing.T) {
 	}
 }
 
+type stringArray []string
+
+func (a stringArray) Value() (driver.Value, error) {
+	return "{" + strings.Join(a, ",") + "}", nil
+}
+
+func TestQueryArgsBindValuerSlice(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	arr := stringArray{"a", "b"}
+	if got := qa.Bind(arr); got != "$1" {
+		t.Fatalf("valuer placeholder mismatch: got %q, want %q", got, "$1")
+	}
+	if want := []any{arr}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+
+	if got := qa.Bind([]stringArray{{"x"}, {"y"}}); got != "($2, $3)" {
+		t.Fatalf("slice of valuers mismatch: got %q, want %q", got, "($2, $3)")
+	}
+}
+
+func TestQueryArgsBindMapsAndNestedSlices(t *testing.T) {
+	t.Parallel()
+
+	doc := map[string]any{"a": 1}
+	matrix := [][]int{{1, 2}, {3}}
+	blobs := [][]byte{[]byte("x"), []byte("y")}
+	docs := []map[string]any{{"a": 1}, {"b": 2}}
+
+	tests := []struct {
+		name     string
+		arg      any
+		want     string
+		wantArgs []any
+	}{
+		{"map is one arg", doc, "$1", []any{doc}},
+		{"nested slice is one arg", matrix, "$1", []any{matrix}},
+		{"nested array is one arg", [2][2]int{{1, 2}, {3, 4}}, "$1", []any{[2][2]int{{1, 2}, {3, 4}}}},
+		{"slice of byte slices expands", blobs, "($1, $2)", []any{[]byte("x"), []byte("y")}},
+		{"slice of maps expands", docs, "($1, $2)", []any{docs[0], docs[1]}},
+		{"byte slice is one arg", []byte{0x1, 0x2}, "$1", []any{[]byte{0x1, 0x2}}},
+		{"empty byte slice is one arg", []byte{}, "$1", []any{[]byte{}}},
+		{"byte array is one byte slice arg", [2]byte{0x1, 0x2}, "$1", []any{[]byte{0x1, 0x2}}},
+		{"slice of byte arrays expands", [][2]byte{{0x1, 0x2}, {0x3, 0x4}}, "($1, $2)", []any{[]byte{0x1, 0x2}, []byte{0x3, 0x4}}},
+		{"byte arrays in any slice expand", []any{[2]byte{0x1, 0x2}, nil}, "($1, $2)", []any{[]byte{0x1, 0x2}, nil}},
+		{"json.RawMessage is one arg", json.RawMessage(`{"a":1}`), "$1", []any{json.RawMessage(`{"a":1}`)}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(DialectPostgres)
+			if got := qa.Bind(tt.arg); got != tt.want {
+				t.Fatalf("placeholder mismatch: got %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
 func TestQueryArgsBindEmptySlice(t *testing.T) {
 	t.Parallel()
 
@@ -94,6 +165,142 @@ func TestQueryArgsBindNil(t *testing.T) {
 	}
 }
 
+func TestQueryArgsBindNamedPositional(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectMySQL)
+	got := []string{
+		qa.BindNamedPositional("user_id", 42),
+		qa.Bind("active"),
+		qa.BindNamedPositional("tags", []string{"a", "b"}),
+	}
+	if want := []string{"?", "?", "(?, ?)"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("placeholders mismatch: got %v, want %v", got, want)
+	}
+
+	wantNames := map[string][]int{"user_id": {1}, "tags": {3, 4}}
+	if names := qa.PositionalNames(); !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("names mismatch: got %v, want %v", names, wantNames)
+	}
+	wantArgs := []any{42, "active", "a", "b"}
+	if !reflect.DeepEqual(qa.args, wantArgs) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, wantArgs)
+	}
+}
+
+func TestQueryArgsBindNamedPositionalEmptyName(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectMySQL)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic for empty positional name")
+		}
+	}()
+	qa.BindNamedPositional("", 1)
+}
+
+func TestQueryArgsBindSQLNamedArg(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	got := []string{
+		qa.Bind(sql.Named("x", 5)),
+		qa.Bind(sql.Named("", "anon")),
+		qa.Bind(sql.Named("ids", []int{7, 8})),
+	}
+	if want := []string{"$1", "$2", "($3, $4)"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("placeholders mismatch: got %v, want %v", got, want)
+	}
+	if want := []any{5, "anon", 7, 8}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+	if want := map[string][]int{"x": {1}, "ids": {3, 4}}; !reflect.DeepEqual(qa.PositionalNames(), want) {
+		t.Fatalf("names mismatch: got %v, want %v", qa.PositionalNames(), want)
+	}
+}
+
+func TestRendererBindNamedOracleReuse(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM orders WHERE tenant_id = {{ bindNamed "tenant" .Tenant }}` +
+		` AND customer_id IN (SELECT id FROM customers WHERE tenant_id = {{ bindNamed "tenant" .Tenant }})` +
+		` AND region_id IN (SELECT id FROM regions WHERE tenant_id = {{ bindNamed "tenant" .Tenant }})` +
+		` AND status = {{ bindNamed "status" .Status }}`
+	data := map[string]any{"Tenant": 42, "Status": "open"}
+
+	r := NewRenderer(DialectOracle).SetStrictArgs(true)
+	out, args, err := r.FromString(tmpl, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `SELECT * FROM orders WHERE tenant_id = :tenant` +
+		` AND customer_id IN (SELECT id FROM customers WHERE tenant_id = :tenant)` +
+		` AND region_id IN (SELECT id FROM regions WHERE tenant_id = :tenant)` +
+		` AND status = :status`
+	if out != want {
+		t.Fatalf("sql mismatch: got %q, want %q", out, want)
+	}
+	if want := []any{sql.Named("tenant", 42), sql.Named("status", "open")}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	out, args, err = r.FromStringWithDialect(tmpl, data, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "tenant_id = $3)") || !reflect.DeepEqual(args, []any{42, 42, 42, "open"}) {
+		t.Fatalf("postgres should bind positionally at every use: got %q, %v", out, args)
+	}
+
+	for _, tmpl := range []string{
+		`{{ bindNamed "t" 1 }} {{ bindNamed "t" 2 }}`,
+		`{{ bindNamed "ids" .IDs }}`,
+		`{{ bindNamed "1bad" 1 }}`,
+	} {
+		if _, _, err := r.FromString(tmpl, map[string]any{"IDs": []int{1, 2}}); err == nil {
+			t.Fatalf("expected error for %s", tmpl)
+		}
+	}
+}
+
+func TestRendererBindNamedOracleByteArray(t *testing.T) {
+	t.Parallel()
+
+	id := [16]byte{1}
+	r := NewRenderer(DialectOracle)
+	out, args, err := r.FromString(`{{ bindNamed "id" .ID }} {{ bindNamed "id" .ID }}`, map[string]any{"ID": id})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := ":id :id"; out != want {
+		t.Fatalf("sql mismatch: got %q, want %q", out, want)
+	}
+	if want := []any{sql.Named("id", make16(1))}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestRendererBindNamedOracleNumericBools(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectOracle).SetOracleNumericBools(true)
+	out, args, err := r.FromString(`WHERE a = {{ bindNamed "f" true }} OR b = {{ bindNamed "f" true }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `WHERE a = :f OR b = :f`; out != want {
+		t.Fatalf("sql mismatch: got %q, want %q", out, want)
+	}
+	if want := []any{sql.Named("f", 1)}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	if _, _, err := r.FromString(`{{ bindNamed "f" true }} {{ bindNamed "f" false }}`, nil); err == nil {
+		t.Fatal("expected error for a name reused with a different bool")
+	}
+}
+
 func TestQueryArgsIdentifierQuoting(t *testing.T) {
 	t.Parallel()
 
@@ -105,9 +312,9 @@ func TestQueryArgsIdentifierQuoting(t *testing.T) {
 	}{
 		{"postgres schema", DialectPostgres, "public.users", `"public"."users"`},
 		{"mysql simple", DialectMySQL, "users", "`users`"},
-		{"sqlite dotted", DialectSQLite, "main.table", "`main`.`table`"},
+		{"sqlite dotted", DialectSQLite, "main.table", `"main"."table"`},
 		{"sqlserver bracket", DialectSQLServer, "dbo.People", `[dbo].[People]`},
-		{"snowflake backtick", DialectSnowflake, "SNOW.TBL", "`SNOW`.`TBL`"},
+		{"snowflake double quote", DialectSnowflake, "SNOW.TBL", `"SNOW"."TBL"`},
 		{"oracle quoted", DialectOracle, "HR.EMP", `"HR"."EMP"`},
 	}
 
@@ -132,6 +339,30 @@ func TestQueryArgsIdentifierQuoting(t *testing.T) {
 	}
 }
 
+func TestRendererSetUppercaseIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectSnowflake).
+		SetUppercaseIdentifiers(true).
+		SetIdentifierTransformer(func(name string) string { return "app_" + name })
+
+	sql, _, err := r.FromString(`SELECT * FROM {{ identifier "analytics.events" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM "APP_ANALYTICS"."EVENTS"`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+
+	sql, _, err = r.SetUppercaseIdentifiers(false).FromString(`{{ identifier "events" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `"app_events"`; sql != want {
+		t.Fatalf("sql mismatch without folding: got %q, want %q", sql, want)
+	}
+}
+
 func TestQueryArgsIdentifierInvalid(t *testing.T) {
 	t.Parallel()
 
@@ -144,6 +375,87 @@ func TestQueryArgsIdentifierInvalid(t *testing.T) {
 	qa.Identifier("users;DROP")
 }
 
+func TestQueryArgsIdentifierMalformedParts(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"a..b", ".users", "users.", ".", "db.schema.table.column"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(DialectPostgres)
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("expected panic for %q", name)
+				}
+			}()
+			qa.Identifier(name)
+		})
+	}
+
+	qa := NewQueryArgs(DialectPostgres)
+	if got, want := qa.Identifier("public.users.id"), `"public"."users"."id"`; got != want {
+		t.Fatalf("three-part identifier mismatch: got %q, want %q", got, want)
+	}
+}
+
+func TestQueryArgsIdentifierPreQuoted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		input   string
+		want    string
+	}{
+		{"postgres quoted", DialectPostgres, `"public"."users"`, `"public"."users"`},
+		{"postgres mixed", DialectPostgres, `"Public".users`, `"Public"."users"`},
+		{"mysql backticks", DialectMySQL, "`app`.`Users`", "`app`.`Users`"},
+		{"sqlserver brackets", DialectSQLServer, "[dbo].People", "[dbo].[People]"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := NewQueryArgs(tt.dialect).Identifier(tt.input); got != tt.want {
+				t.Fatalf("identifier mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{`"a;b"."c"`, `"".users`, `"a"..b`, `"users`} {
+		bad := bad
+		t.Run("invalid "+bad, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("expected panic for %q", bad)
+				}
+			}()
+			NewQueryArgs(DialectPostgres).Identifier(bad)
+		})
+	}
+}
+
+func TestRendererPreQuotedIdentifierSkipsTransforms(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectSnowflake).
+		SetUppercaseIdentifiers(true).
+		SetIdentifierTransformer(func(name string) string { return "app_" + name })
+
+	sql, _, err := r.FromString(`SELECT * FROM {{ identifier "\"Mixed\".\"Case\"" }}, {{ identifier "plain" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM "Mixed"."Case", "APP_PLAIN"`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
 func TestRendererSetDefaultDialect(t *testing.T) {
 	t.Parallel()
 
@@ -161,6 +473,56 @@ func TestRendererSetDefaultDialect(t *testing.T) {
 	}
 }
 
+func TestRendererClone(t *testing.T) {
+	t.Parallel()
+
+	base := NewRenderer(DialectPostgres).
+		AddSearchPath("shared").
+		AddFunc("tenant", func() string { return "acme" }).
+		SetQuoteStyle(DialectMySQL, QuoteDouble).
+		SetDefaultSchema("app").
+		SetArgsPooling(true)
+
+	clone := base.Clone().
+		SetDefaultDialect(DialectMySQL).
+		SetDefaultSchema("reporting").
+		AddSearchPath("extra").
+		AddFunc("region", func() string { return "eu" }).
+		SetQuoteStyle(DialectMySQL, QuoteBacktick)
+
+	if base.defaultDialect != DialectPostgres || base.schema != "app" {
+		t.Fatalf("original config changed: dialect %q, schema %q", base.defaultDialect, base.schema)
+	}
+	if want := []string{"shared"}; !reflect.DeepEqual(base.searchPaths, want) {
+		t.Fatalf("original search paths mismatch: got %v, want %v", base.searchPaths, want)
+	}
+	if _, ok := base.customFuncs["region"]; ok {
+		t.Fatal("clone func leaked into the original")
+	}
+	if base.quoteStyles[DialectMySQL] != QuoteDouble {
+		t.Fatalf("original quote style changed: got %v", base.quoteStyles[DialectMySQL])
+	}
+	if clone.pool == nil || clone.pool == base.pool {
+		t.Fatal("expected the clone to have its own pool")
+	}
+
+	sql, _, err := clone.FromString(`SELECT {{ tenant }}, {{ region }} FROM {{ table "users" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT acme, eu FROM `reporting`.`users`"; sql != want {
+		t.Fatalf("clone sql mismatch: got %q, want %q", sql, want)
+	}
+
+	sql, _, err = base.FromString(`SELECT {{ tenant }} FROM {{ table "users" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT acme FROM "app"."users"`; sql != want {
+		t.Fatalf("original sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
 func TestRendererSearchPaths(t *testing.T) {
 	t.Parallel()
 
@@ -181,6 +543,111 @@ func TestRendererSearchPaths(t *testing.T) {
 	}
 }
 
+func TestRendererIdentifierTransformer(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	out := r.SetIdentifierTransformer(func(name string) string {
+		if strings.Contains(name, ".") {
+			return name
+		}
+		return "app_" + name
+	})
+	if out != r {
+		t.Fatal("SetIdentifierTransformer should return renderer instance")
+	}
+
+	sql, _, err := r.FromString(`SELECT * FROM {{ identifier "users" }} JOIN {{ identifier "audit.log" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM "app_users" JOIN "audit"."log"`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestRendererIdentifierTransformerInvalidOutput(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres).SetIdentifierTransformer(func(name string) string {
+		return name + "; DROP TABLE users"
+	})
+	if _, _, err := r.FromString(`SELECT * FROM {{ identifier "users" }}`, nil); err == nil {
+		t.Fatal("expected error for invalid transformer output")
+	}
+}
+
+func TestRendererTableAndColumnTransformers(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	if out := r.SetTableTransformer(func(name string) string { return "tbl_" + name }); out != r {
+		t.Fatal("SetTableTransformer should return renderer instance")
+	}
+
+	const tmpl = `SELECT {{ columnIdentifier "name" }} FROM {{ tableIdentifier "users" }} WHERE {{ identifier "id" }} = 1`
+	sql, _, err := r.FromString(tmpl, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT "name" FROM "tbl_users" WHERE "id" = 1`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+
+	if out := r.SetColumnTransformer(strings.ToUpper); out != r {
+		t.Fatal("SetColumnTransformer should return renderer instance")
+	}
+	sql, _, err = r.FromString(tmpl, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT "NAME" FROM "tbl_users" WHERE "id" = 1`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestRendererSetDefaultSchema(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	const tmpl = `SELECT * FROM {{ table "orders" }} JOIN {{ table "shared.currencies" }} USING (code)`
+
+	sql, _, err := r.FromString(tmpl, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM "orders" JOIN "shared"."currencies" USING (code)`; sql != want {
+		t.Fatalf("sql mismatch without schema: got %q, want %q", sql, want)
+	}
+
+	sql, _, err = r.SetDefaultSchema("tenant_42").FromStringWithDialect(tmpl, nil, DialectSQLServer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM [tenant_42].[orders] JOIN [shared].[currencies] USING (code)`; sql != want {
+		t.Fatalf("sql mismatch with schema: got %q, want %q", sql, want)
+	}
+
+	if _, _, err := r.SetDefaultSchema("bad schema").FromString(tmpl, nil); err == nil {
+		t.Fatal("expected error for invalid default schema")
+	}
+}
+
+func TestQueryArgsTableAndColumnIdentifierDefaults(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectSQLServer)
+	if got := qa.TableIdentifier("dbo.users"); got != `[dbo].[users]` {
+		t.Fatalf("table identifier mismatch: got %q", got)
+	}
+	if got := qa.ColumnIdentifier("name"); got != `[name]` {
+		t.Fatalf("column identifier mismatch: got %q", got)
+	}
+	if got := qa.TableIdentifier(nil); got != "" {
+		t.Fatalf("expected empty identifier for nil, got %q", got)
+	}
+}
+
 func TestRendererAddFuncs(t *testing.T) {
 	t.Parallel()
 
@@ -198,6 +665,38 @@ func TestRendererAddFuncs(t *testing.T) {
 	}
 }
 
+func TestRendererFromStringWithFuncs(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	r.AddFunc("tenant", func() string { return "shared" })
+
+	const tmpl = `SELECT {{ tenant }}, {{ identifier "users" }}, {{ bind .ID }}`
+	extra := template.FuncMap{
+		"tenant":     func() string { return "acme" },
+		"identifier": func(name string) string { return "x_" + name },
+	}
+
+	sql, args, err := r.FromStringWithFuncs(tmpl, map[string]any{"ID": 5}, DialectPostgres, extra)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT acme, x_users, $1`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{5}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	sql, _, err = r.FromString(tmpl, map[string]any{"ID": 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT shared, "users", $1`; sql != want {
+		t.Fatalf("per-call funcs leaked into a later render: got %q, want %q", sql, want)
+	}
+}
+
 func TestRendererAddFuncInitializesCustomMap(t *testing.T) {
 	t.Parallel()
 
@@ -250,6 +749,90 @@ func TestRendererFromStringWithDialect(t *testing.T) {
 	}
 }
 
+func TestRendererDialectFunc(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT {{ if eq dialect "postgres" }}string_agg(name, ','){{ else }}GROUP_CONCAT(name){{ end }} FROM users WHERE org = {{ bind .Org }} -- {{ dialect }}`
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectPostgres, `SELECT string_agg(name, ',') FROM users WHERE org = $1 -- postgres`},
+		{DialectMySQL, `SELECT GROUP_CONCAT(name) FROM users WHERE org = ? -- mysql`},
+	}
+
+	r := NewRenderer(DialectMySQL)
+	for _, tt := range tests {
+		sql, _, err := r.FromStringWithDialect(tmpl, map[string]any{"Org": 1}, tt.dialect)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sql != tt.want {
+			t.Fatalf("%s sql mismatch: got %q, want %q", tt.dialect, sql, tt.want)
+		}
+	}
+}
+
+func TestRendererFromStringWithArgs(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	sql, args, err := r.FromStringWithArgs(
+		`SELECT * FROM {{ identifier "users" }} WHERE org = {{ bind (index . 0) }} AND id IN {{ bind (index . 1) }}`,
+		DialectPostgres,
+		7, []int{1, 2},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM "users" WHERE org = $1 AND id IN ($2, $3)`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{7, 1, 2}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	if sql, _, err := r.FromStringWithArgs(`SELECT {{ len . }}`, DialectMySQL); err != nil || sql != "SELECT 0" {
+		t.Fatalf("expected empty args as the dot, got %q, %v", sql, err)
+	}
+	if _, _, err := r.FromStringWithArgs(`{{ bind (index . 1) }}`, DialectMySQL, 1); err == nil {
+		t.Fatal("expected error for out-of-range index")
+	}
+}
+
+func TestRendererFromStringWithDialectStructData(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID   int
+		Tags []string
+	}
+	data := struct {
+		User *user
+	}{User: &user{ID: 7, Tags: []string{"a", "b"}}}
+
+	r := NewRenderer(DialectMySQL)
+	sql, args, err := r.FromStringWithDialect(
+		`WHERE id = {{ bind .User.ID }} AND tag IN {{ bind .User.Tags }}`,
+		data,
+		DialectPostgres,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `WHERE id = $1 AND tag IN ($2, $3)`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{7, "a", "b"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	if _, _, err := r.FromStringWithDialect(`WHERE id = {{ bind .Missing }}`, data, DialectPostgres); err == nil {
+		t.Fatal("expected error for unknown struct field")
+	}
+}
+
 func TestRendererFromStringWithDialectCustomFuncs(t *testing.T) {
 	t.Parallel()
 
@@ -297,6 +880,125 @@ func TestRendererFromStringWithDialectExecutionError(t *testing.T) {
 	}
 }
 
+func TestRendererFromStringWithPositionalNames(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	sql, args, names, err := r.FromStringWithPositionalNames(
+		`WHERE user_id = {{ bindNamedPositional "user_id" .ID }} AND org = {{ bind .Org }}`,
+		map[string]any{"ID": 42, "Org": 7},
+		DialectMySQL,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `WHERE user_id = ? AND org = ?`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{42, 7}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+	if want := map[string][]int{"user_id": {1}}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names mismatch: got %v, want %v", names, want)
+	}
+}
+
+func TestRendererFromStringTo(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	var buf strings.Builder
+	args, err := r.FromStringTo(
+		&buf,
+		`INSERT INTO t VALUES {{ range $i, $v := .Rows }}{{ if $i }}, {{ end }}({{ bind $v }}){{ end }}`,
+		map[string]any{"Rows": []int{1, 2, 3}},
+		DialectPostgres,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `INSERT INTO t VALUES ($1), ($2), ($3)`; buf.String() != want {
+		t.Fatalf("sql mismatch: got %q, want %q", buf.String(), want)
+	}
+	if want := []any{1, 2, 3}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	if _, err := r.FromStringTo(&buf, `{{`, nil, DialectPostgres); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestRendererFromStringContext(t *testing.T) {
+	t.Parallel()
+
+	type ctxKey string
+
+	r := NewRenderer(DialectPostgres)
+	ctx := context.WithValue(context.Background(), ctxKey("tenant"), "acme")
+	r.AddFunc("tenantKey", func() ctxKey { return "tenant" })
+
+	sql, args, err := r.FromStringContext(
+		ctx,
+		`WHERE tenant = {{ bind (ctxValue tenantKey) }} AND id = {{ bind .ID }}`,
+		map[string]any{"ID": 1},
+		DialectPostgres,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `WHERE tenant = $1 AND id = $2`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{"acme", 1}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestRendererFromStringContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	r := NewRenderer(DialectPostgres)
+	r.AddFunc("expensive", func() string {
+		called = true
+		return ""
+	})
+
+	_, _, err := r.FromStringContext(ctx, `{{ expensive }}`, nil, DialectPostgres)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if called {
+		t.Fatal("template should not execute after cancellation")
+	}
+}
+
+func TestRendererFromTemplateContext(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "q.sql"), []byte(`WHERE id = {{ bind .ID }}`), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	r := NewRenderer(DialectPostgres).AddSearchPath(dir)
+	sql, args, err := r.FromTemplateContext(context.Background(), "q.sql", map[string]any{"ID": 3}, DialectMySQL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sql != "WHERE id = ?" || !reflect.DeepEqual(args, []any{3}) {
+		t.Fatalf("unexpected result: %q %v", sql, args)
+	}
+
+	if _, _, err := r.FromTemplateContext(context.Background(), "missing.sql", nil, DialectMySQL); err == nil {
+		t.Fatal("expected error for missing template")
+	}
+}
+
 func TestRendererFromStringUsesDefaultDialect(t *testing.T) {
 	t.Parallel()
 
@@ -320,6 +1022,46 @@ func TestRendererFromStringUsesDefaultDialect(t *testing.T) {
 	}
 }
 
+func TestRendererFromStringDebug(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	sql, err := r.FromStringDebug(
+		`SELECT * FROM users WHERE id IN {{ bind .IDs }} AND name = {{ bind .Name }} AND deleted_at IS {{ bind .Deleted }}`,
+		map[string]any{"IDs": []int{1, 2}, "Name": "O'Brien", "Deleted": nil},
+		DialectPostgres,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `SELECT * FROM users WHERE id IN (1, 2) AND name = 'O''Brien' AND deleted_at IS NULL`
+	if sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestRendererFromTemplateDebug(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "q.sql"), []byte(`WHERE id = {{ bind .ID }}`), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	r := NewRenderer(DialectMySQL).AddSearchPath(dir)
+	sql, err := r.FromTemplateDebug("q.sql", map[string]any{"ID": 9}, DialectSQLServer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sql != "WHERE id = 9" {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, "WHERE id = 9")
+	}
+
+	if _, err := r.FromTemplateDebug("missing.sql", nil, DialectSQLServer); err == nil {
+		t.Fatal("expected error for missing template")
+	}
+}
+
 func TestRendererFromTemplateWithDialectDirectPath(t *testing.T) {
 	t.Parallel()
 
@@ -361,6 +1103,30 @@ func TestRendererFromTemplateWithDialectReadError(t *testing.T) {
 	}
 }
 
+func TestRendererFromTemplateWithDialectParseError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.sql")
+	if err := os.WriteFile(path, []byte(`SELECT * FROM t WHERE id = {{ bind .X`), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	r := NewRenderer(DialectMySQL).AddSearchPath(dir)
+	_, _, err := r.FromTemplateWithDialect("broken.sql", nil, DialectMySQL)
+	if err == nil {
+		t.Fatal("expected parse error")
+	}
+	if want := fmt.Sprintf("failed to parse %q", path); !strings.Contains(err.Error(), want) {
+		t.Fatalf("error should name the file: got %v, want it to contain %s", err, want)
+	}
+
+	_, _, err = r.FromStringWithDialect(`{{ bind .X`, nil, DialectMySQL)
+	if err == nil || strings.Contains(err.Error(), "failed to parse") {
+		t.Fatalf("string renders should keep the plain parse error, got %v", err)
+	}
+}
+
 func TestRendererFromTemplateWithDialectSearchPath(t *testing.T) {
 	t.Parallel()
 
@@ -388,6 +1154,99 @@ func TestRendererFromTemplateWithDialectSearchPath(t *testing.T) {
 	}
 }
 
+func TestRendererFromTemplateRecursiveSearchPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTemplate := func(rel, content string) {
+		t.Helper()
+		full := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(full), 0o700); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write template: %v", err)
+		}
+	}
+	writeTemplate("users/find_user.sql", `SELECT * FROM users WHERE id = {{ bind .ID }}`)
+	writeTemplate("users/list.sql", `SELECT * FROM users`)
+	writeTemplate("orders/list.sql", `SELECT * FROM orders`)
+
+	r := NewRenderer(DialectPostgres).AddSearchPathRecursive(dir).SetDefaultExtension(".sql")
+
+	sql, _, err := r.FromTemplate("find_user", map[string]any{"ID": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM users WHERE id = $1`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+
+	sql, _, err = r.FromTemplate("orders/list.sql", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `SELECT * FROM orders`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+
+	_, _, err = r.FromTemplate("list.sql", nil)
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") ||
+		!strings.Contains(err.Error(), filepath.Join("orders", "list.sql")) ||
+		!strings.Contains(err.Error(), filepath.Join("users", "list.sql")) {
+		t.Fatalf("expected ambiguity error naming both candidates, got %v", err)
+	}
+}
+
+func TestRendererResolveTemplate(t *testing.T) {
+	t.Parallel()
+
+	stale, current, tree := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, path := range []string{
+		filepath.Join(stale, "report.sql"),
+		filepath.Join(current, "report.sql"),
+		filepath.Join(current, "only.sql"),
+	} {
+		if err := os.WriteFile(path, []byte(`SELECT 1`), 0o600); err != nil {
+			t.Fatalf("failed to write template: %v", err)
+		}
+	}
+
+	r := NewRenderer(DialectPostgres).
+		AddSearchPath(stale).
+		AddSearchPath(current).
+		AddSearchPathRecursive(tree).
+		SetDefaultExtension(".sql")
+
+	got, err := r.ResolveTemplate("report")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := filepath.Join(stale, "report.sql"); got != want {
+		t.Fatalf("earlier search path should win: got %q, want %q", got, want)
+	}
+	if got, err := r.ResolveTemplate("only.sql"); err != nil || got != filepath.Join(current, "only.sql") {
+		t.Fatalf("resolve mismatch: got %q, %v", got, err)
+	}
+	if _, err := r.ResolveTemplate("missing"); err == nil {
+		t.Fatal("expected error for missing template")
+	}
+
+	want := []string{
+		"report",
+		filepath.Join(stale, "report"),
+		filepath.Join(current, "report"),
+		"report.sql",
+		filepath.Join(stale, "report.sql"),
+		filepath.Join(current, "report.sql"),
+		filepath.Join(tree, "**", "report"),
+		filepath.Join(tree, "**", "report.sql"),
+	}
+	if got := r.TemplateSearchOrder("report"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("search order mismatch:\ngot  %q\nwant %q", got, want)
+	}
+}
+
 func TestRendererFromTemplateNotFound(t *testing.T) {
 	t.Parallel()
 
@@ -406,6 +1265,43 @@ func TestRendererFromTemplateNotFound(t *testing.T) {
 	}
 }
 
+func TestRendererDefaultExtension(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "users_by_id.sql"), []byte(`WHERE id = {{ bind .ID }}`), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	r := NewRenderer(DialectPostgres).AddSearchPath(dir)
+	if _, _, err := r.FromTemplate("users_by_id", map[string]any{"ID": 1}); err == nil {
+		t.Fatal("expected error without default extension")
+	}
+
+	if out := r.SetDefaultExtension(".sql"); out != r {
+		t.Fatal("SetDefaultExtension should return renderer instance")
+	}
+	sql, args, err := r.FromTemplate("users_by_id", map[string]any{"ID": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sql != "WHERE id = $1" || !reflect.DeepEqual(args, []any{1}) {
+		t.Fatalf("unexpected result: %q %v", sql, args)
+	}
+
+	if _, _, err := r.FromTemplate("users_by_id.sql", map[string]any{"ID": 1}); err != nil {
+		t.Fatalf("explicit extension should still resolve: %v", err)
+	}
+
+	_, _, err = r.FromTemplate("missing", nil)
+	if err == nil {
+		t.Fatal("expected error for missing template")
+	}
+	if !strings.Contains(err.Error(), `"missing.sql"`) {
+		t.Fatalf("error should list the names tried: %v", err)
+	}
+}
+
 func TestRendererFromTemplateUsesDefaultDialect(t *testing.T) {
 	t.Parallel()
 
@@ -432,3 +1328,51 @@ func TestRendererFromTemplateUsesDefaultDialect(t *testing.T) {
 		t.Fatalf("args mismatch: got %v, want %v", args, wantArgs)
 	}
 }
+
+func TestSortedKeys(t *testing.T) {
+	t.Parallel()
+
+	got := sortedKeys(map[string]int{"b": 1, "c": 2, "a": 3})
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys mismatch: got %v, want %v", got, want)
+	}
+}
+
+func TestRendererMustFromString(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	sql, args := r.MustFromString(`WHERE id = {{ bind .ID }}`, map[string]any{"ID": 1})
+	if sql != "WHERE id = $1" || !reflect.DeepEqual(args, []any{1}) {
+		t.Fatalf("unexpected result: %q %v", sql, args)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic for invalid template")
+		}
+	}()
+	r.MustFromString(`{{`, nil)
+}
+
+func TestRendererMustFromTemplate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "q.sql"), []byte(`WHERE id = {{ bind .ID }}`), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	r := NewRenderer(DialectSQLServer).AddSearchPath(dir)
+	sql, args := r.MustFromTemplate("q.sql", map[string]any{"ID": 1})
+	if sql != "WHERE id = @p1" || !reflect.DeepEqual(args, []any{1}) {
+		t.Fatalf("unexpected result: %q %v", sql, args)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic for missing template")
+		}
+	}()
+	r.MustFromTemplate("missing.sql", nil)
+}
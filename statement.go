@@ -0,0 +1,143 @@
+package sqlrender
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Statement is a rendered SQL statement together with everything needed to
+// execute or log it.
+type Statement struct {
+	SQL       string
+	Args      []any
+	NamedArgs []sql.NamedArg
+	Dialect   Dialect
+	// SourcePath is the absolute path of the template file the statement was
+	// rendered from, as resolved against the search paths. It is empty for
+	// statements rendered from strings.
+	SourcePath string
+	// Timings holds the cumulative time spent in each custom template
+	// function when the renderer has profiling enabled.
+	Timings map[string]time.Duration
+
+	warnings []string
+}
+
+// Warnings returns the diagnostics recorded while rendering the statement
+// when the renderer has warnings enabled, in the order they were raised.
+func (s *Statement) Warnings() []string {
+	return s.warnings
+}
+
+// RenderString renders the template string using the supplied dialect and
+// returns the result as a Statement.
+func (r *Renderer) RenderString(s string, data any, dialect Dialect) (*Statement, error) {
+	qa := r.newQueryArgs(dialect)
+	out, err := r.render(s, data, qa)
+	if err != nil {
+		return nil, err
+	}
+	return newStatement(out, qa), nil
+}
+
+// RenderTemplate loads the named template file and renders it using the
+// supplied dialect, returning the result as a Statement.
+func (r *Renderer) RenderTemplate(name string, data any, dialect Dialect) (*Statement, error) {
+	qa := r.newQueryArgs(dialect)
+	out, err := r.renderTemplate(context.Background(), name, data, qa)
+	if err != nil {
+		return nil, err
+	}
+	return newStatement(out, qa), nil
+}
+
+func newStatement(out string, qa *QueryArgs) *Statement {
+	return &Statement{
+		SQL:        out,
+		Args:       qa.args,
+		NamedArgs:  qa.namedArgs(),
+		Dialect:    qa.dialect,
+		SourcePath: qa.sourcePath,
+		Timings:    qa.timings,
+		warnings:   qa.warnings,
+	}
+}
+
+// ArgMap returns the arguments keyed by bind variable name, for drivers and
+// APIs that execute named parameters rather than a positional slice. It is
+// only defined when every argument was bound by name, as bindNamed does on
+// Oracle, where the SQL references `:name`; for a statement with any
+// positional argument, or none at all, it returns nil. Names recorded by
+// bindNamedPositional label positional placeholders and are not used; see
+// NamedArgs for those.
+func (s *Statement) ArgMap() map[string]any {
+	if len(s.Args) == 0 {
+		return nil
+	}
+
+	m := make(map[string]any, len(s.Args))
+	for _, arg := range s.Args {
+		named, ok := arg.(sql.NamedArg)
+		if !ok {
+			return nil
+		}
+		m[named.Name] = named.Value
+	}
+	return m
+}
+
+// namedArgs pairs every name recorded by BindNamedPositional with the value
+// bound at its first position, ordered by that position.
+func (qa *QueryArgs) namedArgs() []sql.NamedArg {
+	if len(qa.names) == 0 {
+		return nil
+	}
+
+	named := make([]sql.NamedArg, 0, len(qa.names))
+	for name, positions := range qa.names {
+		named = append(named, sql.Named(name, qa.args[positions[0]-1]))
+	}
+	sort.Slice(named, func(i, j int) bool {
+		return qa.names[named[i].Name][0] < qa.names[named[j].Name][0]
+	})
+	return named
+}
+
+// FromStringBatch parses s once and renders it for every row, each with its
+// own binder, returning one Statement per row in order. A parse error fails
+// the batch before any row is executed; an execution error names the row.
+func (r *Renderer) FromStringBatch(s string, rows []map[string]any, dialect Dialect) ([]Statement, error) {
+	ctx := context.Background()
+	tmpl, err := r.parse(ctx, "sql", s, r.newQueryArgs(dialect))
+	if err != nil {
+		return nil, err
+	}
+
+	stmts := make([]Statement, len(rows))
+	for i, row := range rows {
+		qa := r.newQueryArgs(dialect)
+		rowTmpl, err := tmpl.Clone()
+		if err != nil {
+			return nil, err
+		}
+		rowTmpl.Funcs(r.funcMap(ctx, qa))
+
+		var data any = row
+		if row == nil {
+			data = map[string]any{}
+		}
+		start := time.Now()
+		var buf bytes.Buffer
+		if err := r.execute(&buf, rowTmpl, data, qa); err != nil {
+			r.observe(qa, "", start, err)
+			return nil, fmt.Errorf("sqlrender: batch row %d: %w", i, err)
+		}
+		r.observe(qa, buf.String(), start, nil)
+		stmts[i] = *newStatement(buf.String(), qa)
+	}
+	return stmts, nil
+}
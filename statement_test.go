@@ -0,0 +1,200 @@
+package sqlrender
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRendererRenderString(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	stmt, err := r.RenderString(
+		`WHERE org = {{ bindNamedPositional "org" .Org }} AND id IN {{ bind .IDs }} AND user_id = {{ bindNamedPositional "user_id" .User }}`,
+		map[string]any{"Org": 3, "IDs": []int{1, 2}, "User": 9},
+		DialectPostgres,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &Statement{
+		SQL:       `WHERE org = $1 AND id IN ($2, $3) AND user_id = $4`,
+		Args:      []any{3, 1, 2, 9},
+		NamedArgs: []sql.NamedArg{sql.Named("org", 3), sql.Named("user_id", 9)},
+		Dialect:   DialectPostgres,
+	}
+	if !reflect.DeepEqual(stmt, want) {
+		t.Fatalf("statement mismatch: got %+v, want %+v", stmt, want)
+	}
+}
+
+func TestRendererRenderStringError(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL)
+	if _, err := r.RenderString(`{{`, nil, DialectMySQL); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestRendererRenderTemplate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "q.sql"), []byte(`WHERE id = {{ bind .ID }}`), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	r := NewRenderer(DialectMySQL).AddSearchPath(dir)
+	stmt, err := r.RenderTemplate("q.sql", map[string]any{"ID": 5}, DialectOracle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stmt.SQL != "WHERE id = :1" {
+		t.Fatalf("sql mismatch: got %q, want %q", stmt.SQL, "WHERE id = :1")
+	}
+	if !reflect.DeepEqual(stmt.Args, []any{5}) {
+		t.Fatalf("args mismatch: got %v", stmt.Args)
+	}
+	if stmt.Dialect != DialectOracle {
+		t.Fatalf("dialect mismatch: got %q", stmt.Dialect)
+	}
+	if stmt.NamedArgs != nil {
+		t.Fatalf("expected no named args, got %v", stmt.NamedArgs)
+	}
+
+	if _, err := r.RenderTemplate("missing.sql", nil, DialectOracle); err == nil {
+		t.Fatal("expected error for missing template")
+	}
+}
+
+func TestRendererRenderTemplateSourcePath(t *testing.T) {
+	t.Parallel()
+
+	first, second := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(second, "users"), 0o700); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	want := filepath.Join(second, "users", "by_id.sql")
+	if err := os.WriteFile(want, []byte(`SELECT 1`), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	r := NewRenderer(DialectPostgres).AddSearchPath(first).AddSearchPath(second).SetDefaultExtension(".sql")
+	stmt, err := r.RenderTemplate("users/by_id", nil, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stmt.SourcePath != want {
+		t.Fatalf("source path mismatch: got %q, want %q", stmt.SourcePath, want)
+	}
+
+	stmt, err = r.RenderString(`SELECT 1`, nil, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stmt.SourcePath != "" {
+		t.Fatalf("expected no source path for a string render, got %q", stmt.SourcePath)
+	}
+}
+
+func TestRendererFromStringBatch(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	rows := []map[string]any{
+		{"ID": 1, "Tags": []string{"a", "b"}},
+		{"ID": 2, "Tags": []string{"c"}},
+		nil,
+	}
+	stmts, err := r.FromStringBatch(
+		`UPDATE items SET tags = {{ bind .Tags }} WHERE id = {{ bind .ID }}`,
+		rows,
+		DialectPostgres,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stmts) != 3 {
+		t.Fatalf("statement count mismatch: got %d, want 3", len(stmts))
+	}
+
+	want := []struct {
+		sql  string
+		args []any
+	}{
+		{`UPDATE items SET tags = ($1, $2) WHERE id = $3`, []any{"a", "b", 1}},
+		{`UPDATE items SET tags = ($1) WHERE id = $2`, []any{"c", 2}},
+		{`UPDATE items SET tags = $1 WHERE id = $2`, []any{nil, nil}},
+	}
+	for i, w := range want {
+		if stmts[i].SQL != w.sql {
+			t.Fatalf("row %d sql mismatch: got %q, want %q", i, stmts[i].SQL, w.sql)
+		}
+		if !reflect.DeepEqual(stmts[i].Args, w.args) {
+			t.Fatalf("row %d args mismatch: got %v, want %v", i, stmts[i].Args, w.args)
+		}
+		if stmts[i].Dialect != DialectPostgres {
+			t.Fatalf("row %d dialect mismatch: got %q", i, stmts[i].Dialect)
+		}
+	}
+}
+
+func TestRendererFromStringBatchErrors(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	calls := 0
+	r.AddFunc("count", func() string { calls++; return "" })
+
+	if _, err := r.FromStringBatch(`{{ count }}{{ if }}`, []map[string]any{{}, {}}, DialectPostgres); err == nil {
+		t.Fatal("expected parse error")
+	}
+	if calls != 0 {
+		t.Fatalf("expected no execution after a parse error, got %d calls", calls)
+	}
+
+	_, err := r.FromStringBatch(`{{ identifier .Name }}`, []map[string]any{{"Name": "ok"}, {"Name": "bad name"}}, DialectPostgres)
+	if err == nil || !strings.Contains(err.Error(), "batch row 1") {
+		t.Fatalf("expected row error, got %v", err)
+	}
+}
+
+func TestStatementArgMap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		tmpl    string
+		want    map[string]any
+	}{
+		{"oracle named", DialectOracle,
+			`WHERE tenant_id = {{ bindNamed "tenant" .Tenant }} AND id = {{ bindNamed "id" .ID }} OR owner = {{ bindNamed "tenant" .Tenant }}`,
+			map[string]any{"tenant": 3, "id": 9}},
+		{"oracle mixed", DialectOracle, `WHERE tenant_id = {{ bindNamed "tenant" .Tenant }} AND id = {{ bind .ID }}`, nil},
+		{"positional", DialectPostgres, `WHERE id = {{ bind .ID }}`, nil},
+		{"named positional", DialectPostgres, `WHERE id = {{ bindNamed "id" .ID }}`, nil},
+		{"no args", DialectOracle, `SELECT 1 FROM dual`, nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stmt, err := NewRenderer(tt.dialect).RenderString(tt.tmpl, map[string]any{"Tenant": 3, "ID": 9}, tt.dialect)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := stmt.ArgMap(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("arg map mismatch: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
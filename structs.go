@@ -0,0 +1,389 @@
+package sqlrender
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// structField is an exported struct field mapped to a column name.
+type structField struct {
+	name   string
+	column string
+	value  reflect.Value
+}
+
+// structFields returns the column-mapped exported fields of v, which must be a
+// struct or a non-nil pointer to one. The column name comes from the `db` tag
+// (anything after a comma is ignored) or, without a tag, the field name;
+// fields tagged `db:"-"` are skipped. Untagged exported embedded structs (or
+// pointers to them) are flattened into the parent, except for types that
+// implement driver.Valuer; a nil embedded pointer contributes no columns.
+func structFields(v any) ([]structField, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("sqlrender: expected a struct, got nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sqlrender: expected a struct, got %T", v)
+	}
+
+	fields := appendStructFields(nil, rv)
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if seen[f.column] {
+			return nil, fmt.Errorf("sqlrender: duplicate column %q in %T", f.column, v)
+		}
+		seen[f.column] = true
+	}
+	return fields, nil
+}
+
+func appendStructFields(fields []structField, rv reflect.Value) []structField {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag, tagged := f.Tag.Lookup("db")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" && isEmbeddedStruct(f.Type) {
+			fv := rv.Field(i)
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			fields = appendStructFields(fields, fv)
+			continue
+		}
+
+		column := f.Name
+		if tagged && name != "" {
+			column = name
+		}
+		fields = append(fields, structField{name: f.Name, column: column, value: rv.Field(i)})
+	}
+	return fields
+}
+
+// isEmbeddedStruct reports whether an anonymous field of type t should be
+// flattened rather than mapped to a column of its own.
+func isEmbeddedStruct(t reflect.Type) bool {
+	if t.Implements(valuerType) || reflect.PointerTo(t).Implements(valuerType) {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// InsertOption customizes which struct fields InsertColumns renders.
+type InsertOption func(*insertConfig)
+
+type insertConfig struct {
+	skip     []string
+	skipZero bool
+}
+
+// InsertSkip omits the named columns so the database default applies. Naming
+// a column the struct does not have is an error.
+func InsertSkip(columns ...string) InsertOption {
+	return func(c *insertConfig) {
+		c.skip = append(c.skip, columns...)
+	}
+}
+
+// InsertSkipZero omits every zero-valued field.
+func InsertSkipZero() InsertOption {
+	return func(c *insertConfig) {
+		c.skipZero = true
+	}
+}
+
+// InsertColumns renders the two halves of an INSERT from the exported fields
+// of v: the quoted column list and the matching placeholders, e.g.
+// `"name", "email"` and `$1, $2`, to be written as
+// `INSERT INTO t (cols) VALUES (vals)`. Columns come from `db` tags, with
+// embedded structs flattened (see structFields). Structs without any column
+// left after the options apply are errors.
+func (qa *QueryArgs) InsertColumns(v any, opts ...InsertOption) (cols, vals string, err error) {
+	var cfg insertConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	fields, err := structFields(v)
+	if err != nil {
+		return "", "", err
+	}
+
+	skipped := make(map[string]bool, len(cfg.skip))
+	for _, name := range cfg.skip {
+		skipped[name] = false
+	}
+
+	var colList, valList []string
+	for _, f := range fields {
+		if _, ok := skipped[f.column]; ok {
+			skipped[f.column] = true
+			continue
+		}
+		if cfg.skipZero && f.value.IsZero() {
+			continue
+		}
+
+		col, err := qa.identifier(f.column)
+		if err != nil {
+			return "", "", err
+		}
+		colList = append(colList, col)
+		valList = append(valList, qa.bindScalar(f.value))
+	}
+
+	for _, name := range sortedKeys(skipped) {
+		if !skipped[name] {
+			return "", "", fmt.Errorf("sqlrender: unknown column %q in skip list for %T", name, v)
+		}
+	}
+	if len(colList) == 0 {
+		return "", "", fmt.Errorf("sqlrender: no columns left to insert for %T", v)
+	}
+
+	return strings.Join(colList, ", "), strings.Join(valList, ", "), nil
+}
+
+// InsertClause holds the two halves rendered by Insert.
+type InsertClause struct {
+	Columns string
+	Values  string
+}
+
+// Insert is the template form of InsertColumns, backing the `insert` func:
+//
+//	{{ $ins := insert .User "id" }}
+//	INSERT INTO users ({{ $ins.Columns }}) VALUES ({{ $ins.Values }})
+//
+// The values are bound when Insert runs, so on dialects with `?`
+// placeholders nothing else may be bound between the call and the VALUES
+// list.
+func (qa *QueryArgs) Insert(v any, skip ...string) (InsertClause, error) {
+	cols, vals, err := qa.InsertColumns(v, InsertSkip(skip...))
+	if err != nil {
+		return InsertClause{}, err
+	}
+	return InsertClause{Columns: cols, Values: vals}, nil
+}
+
+// InsertStruct renders the column list and VALUES tuple of an INSERT from the
+// exported fields of v, e.g. `("name", "email") VALUES ($1, $2)`. Columns come
+// from `db` tags (see structFields). Columns named in skip are omitted so the
+// database default applies, and with skipZero every zero-valued field is
+// omitted as well. Unknown skip names and structs without any remaining
+// column are errors.
+func (qa *QueryArgs) InsertStruct(v any, skipZero bool, skip ...string) (string, error) {
+	opts := []InsertOption{InsertSkip(skip...)}
+	if skipZero {
+		opts = append(opts, InsertSkipZero())
+	}
+
+	cols, vals, err := qa.InsertColumns(v, opts...)
+	if err != nil {
+		return "", err
+	}
+	return "(" + cols + ") VALUES (" + vals + ")", nil
+}
+
+// ValuesFields renders the row tuples of a multi-row VALUES list from a slice
+// (or array) of structs, binding only the named fields in the given order:
+// `($1, $2), ($3, $4)`. A field is matched by its column name (the `db` tag)
+// or its Go field name. Rows may be structs or non-nil pointers to them. An
+// empty row list or field list, or a field a row does not have, panics so
+// the render fails.
+func (qa *QueryArgs) ValuesFields(rows any, fields []string) string {
+	if len(fields) == 0 {
+		panic("sqlrender: valuesFields requires at least one field")
+	}
+
+	rv := reflect.ValueOf(rows)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		panic(fmt.Sprintf("sqlrender: valuesFields expects a slice of structs, got %T", rows))
+	}
+	if rv.Len() == 0 {
+		panic("sqlrender: valuesFields requires at least one row")
+	}
+
+	tuples := make([]string, rv.Len())
+	for i := range tuples {
+		row := rv.Index(i).Interface()
+		rowFields, err := structFields(row)
+		if err != nil {
+			panic(fmt.Sprintf("sqlrender: valuesFields row %d: %v", i, err))
+		}
+
+		byName := fieldsByName(rowFields)
+		placeholders := make([]string, len(fields))
+		for j, name := range fields {
+			f, ok := byName[name]
+			if !ok {
+				panic(fmt.Sprintf("sqlrender: valuesFields row %d: %T has no field %q", i, row, name))
+			}
+			placeholders[j] = qa.bindScalar(f.value)
+		}
+		tuples[i] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	return strings.Join(tuples, ", ")
+}
+
+// ValuesAs renders a derived table of bound rows,
+// `(VALUES ($1, $2), ($3, $4)) AS "t"("id", "val")`, for joining an UPDATE
+// or SELECT against a batch of values. Each row is a slice or array with one
+// value per column, or a struct (or pointer to one) whose fields are matched
+// to the columns like ValuesFields. Every cell binds as one argument, so a
+// slice fills an array column rather than expanding. types optionally casts every value of a
+// column, e.g. "int" and "text", since Postgres cannot always infer a
+// placeholder's type; it must have one entry per column, and an empty entry
+// leaves that column uncast.
+//
+// MySQL gets its `VALUES ROW(...)` form (8.0.19+). SQLite and Oracle have no
+// column list for derived tables, so they panic, as do an empty row list, a
+// row of the wrong length and a qualified alias.
+func (qa *QueryArgs) ValuesAs(alias string, columns []string, rows any, types ...string) string {
+	if qa.dialect == DialectSQLite || qa.dialect == DialectOracle {
+		panic(fmt.Sprintf("sqlrender: valuesAs is not supported for dialect %s", qa.dialect))
+	}
+	if strings.Contains(alias, ".") {
+		panic(fmt.Sprintf("sqlrender: valuesAs alias %q must not be qualified", alias))
+	}
+	if len(columns) == 0 {
+		panic("sqlrender: valuesAs requires at least one column")
+	}
+	if len(types) > 0 && len(types) != len(columns) {
+		panic(fmt.Sprintf("sqlrender: valuesAs got %d types for %d columns", len(types), len(columns)))
+	}
+	for _, typ := range types {
+		if typ == "" {
+			continue
+		}
+		if err := validateCastType(typ); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	rv := reflect.ValueOf(rows)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		panic(fmt.Sprintf("sqlrender: valuesAs expects a slice of rows, got %T", rows))
+	}
+	if rv.Len() == 0 {
+		panic("sqlrender: valuesAs requires at least one row")
+	}
+
+	rowPrefix := "("
+	if qa.dialect == DialectMySQL {
+		rowPrefix = "ROW("
+	}
+	tuples := make([]string, rv.Len())
+	for i := range tuples {
+		values := valuesAsRow(rv.Index(i), columns, i)
+		placeholders := make([]string, len(values))
+		for j, value := range values {
+			placeholders[j] = qa.bindScalar(reflect.ValueOf(value))
+			if len(types) > 0 && types[j] != "" {
+				if qa.dialect == DialectPostgres {
+					placeholders[j] += "::" + types[j]
+				} else {
+					placeholders[j] = "CAST(" + placeholders[j] + " AS " + types[j] + ")"
+				}
+			}
+		}
+		tuples[i] = rowPrefix + strings.Join(placeholders, ", ") + ")"
+	}
+
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = qa.mustIdentifier(col)
+	}
+	return "(VALUES " + strings.Join(tuples, ", ") + ") AS " + qa.mustIdentifier(alias) +
+		"(" + strings.Join(quoted, ", ") + ")"
+}
+
+// valuesAsRow returns the values of row i for ValuesAs in column order.
+func valuesAsRow(row reflect.Value, columns []string, i int) []any {
+	for row.Kind() == reflect.Interface && !row.IsNil() {
+		row = row.Elem()
+	}
+
+	if row.Kind() == reflect.Slice || row.Kind() == reflect.Array {
+		if row.Len() != len(columns) {
+			panic(fmt.Sprintf("sqlrender: valuesAs row %d has %d values for %d columns", i, row.Len(), len(columns)))
+		}
+		values := make([]any, row.Len())
+		for j := range values {
+			values[j] = row.Index(j).Interface()
+		}
+		return values
+	}
+
+	fields, err := structFields(row.Interface())
+	if err != nil {
+		panic(fmt.Sprintf("sqlrender: valuesAs row %d: %v", i, err))
+	}
+	byName := fieldsByName(fields)
+	values := make([]any, len(columns))
+	for j, col := range columns {
+		f, ok := byName[col]
+		if !ok {
+			panic(fmt.Sprintf("sqlrender: valuesAs row %d: %s has no field %q", i, row.Type(), col))
+		}
+		values[j] = f.value.Interface()
+	}
+	return values
+}
+
+// SetChanged renders an UPDATE assignment list like Set, but from the fields
+// of struct v named in changed, in that order: `"name" = $1, "email" = $2`.
+// Fields are matched by column name (the `db` tag) or Go field name, and the
+// column is always the mapped one. It suits partial updates that track dirty
+// fields. No changed fields render an empty string; an unknown name panics so
+// the render fails.
+func (qa *QueryArgs) SetChanged(v any, changed []string) string {
+	fields, err := structFields(v)
+	if err != nil {
+		panic(err.Error())
+	}
+	byName := fieldsByName(fields)
+
+	parts := make([]string, len(changed))
+	for i, name := range changed {
+		f, ok := byName[name]
+		if !ok {
+			panic(fmt.Sprintf("sqlrender: setChanged: %T has no field %q", v, name))
+		}
+		parts[i] = qa.mustIdentifier(f.column) + " = " + qa.bindScalar(f.value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fieldsByName indexes fields by Go field name and by column name, columns
+// taking precedence when the two collide.
+func fieldsByName(fields []structField) map[string]structField {
+	byName := make(map[string]structField, 2*len(fields))
+	for _, f := range fields {
+		byName[f.name] = f
+	}
+	for _, f := range fields {
+		byName[f.column] = f
+	}
+	return byName
+}
@@ -0,0 +1,440 @@
+package sqlrender
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type insertUser struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	Email     string    `db:"email,omitempty"`
+	CreatedAt time.Time `db:"created_at"`
+	Secret    string    `db:"-"`
+	Plain     int
+	internal  string
+}
+
+type taggedPost struct {
+	ID   int      `db:"id"`
+	Tags []string `db:"tags"`
+}
+
+func TestStructFields(t *testing.T) {
+	t.Parallel()
+
+	fields, err := structFields(&insertUser{internal: "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, f := range fields {
+		got = append(got, f.column)
+	}
+	if want := []string{"id", "name", "email", "created_at", "Plain"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("columns mismatch: got %v, want %v", got, want)
+	}
+
+	if _, err := structFields(42); err == nil {
+		t.Fatal("expected error for non-struct")
+	}
+	if _, err := structFields((*insertUser)(nil)); err == nil {
+		t.Fatal("expected error for nil pointer")
+	}
+}
+
+func TestQueryArgsInsertStructSkip(t *testing.T) {
+	t.Parallel()
+
+	u := insertUser{ID: 1, Name: "ann", Email: "a@x", Plain: 3}
+	qa := NewQueryArgs(DialectPostgres)
+	got, err := qa.InsertStruct(u, false, "id", "created_at")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `("name", "email", "Plain") VALUES ($1, $2, $3)`; got != want {
+		t.Fatalf("insert mismatch: got %q, want %q", got, want)
+	}
+	if want := []any{"ann", "a@x", 3}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+}
+
+func TestQueryArgsInsertStructSkipZero(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectMySQL)
+	got, err := qa.InsertStruct(&insertUser{Name: "bob"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "(`name`) VALUES (?)"; got != want {
+		t.Fatalf("insert mismatch: got %q, want %q", got, want)
+	}
+}
+
+func TestQueryArgsInsertStructErrors(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	if _, err := qa.InsertStruct(insertUser{}, false, "nope"); err == nil || !strings.Contains(err.Error(), `"nope"`) {
+		t.Fatalf("expected unknown skip column error, got %v", err)
+	}
+	if _, err := qa.InsertStruct(insertUser{}, true); err == nil {
+		t.Fatal("expected error when every column is skipped")
+	}
+	if _, err := qa.InsertStruct("x", false); err == nil {
+		t.Fatal("expected error for non-struct")
+	}
+}
+
+func TestRendererInsertStruct(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectSQLServer)
+	sql, args, err := r.FromString(
+		`INSERT INTO users {{ insertStruct .User false "id" "created_at" "Plain" }}`,
+		map[string]any{"User": insertUser{ID: 5, Name: "cy", Email: "c@x"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `INSERT INTO users ([name], [email]) VALUES (@p1, @p2)`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{"cy", "c@x"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+type auditFields struct {
+	CreatedBy string `db:"created_by"`
+	UpdatedBy string `db:"updated_by"`
+}
+
+type Tenant struct {
+	TenantID int `db:"tenant_id"`
+}
+
+type insertDoc struct {
+	ID int `db:"id"`
+	auditFields
+	*Tenant
+	Title string      `db:"title"`
+	Meta  auditFields `db:"-"`
+}
+
+type AuditFields = auditFields
+
+type insertNote struct {
+	AuditFields
+	Body string `db:"body"`
+}
+
+func TestStructFieldsEmbedded(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		v    any
+		want []string
+	}{
+		{"unexported embed is skipped", insertDoc{Tenant: &Tenant{TenantID: 4}}, []string{"id", "tenant_id", "title"}},
+		{"nil embedded pointer", insertDoc{}, []string{"id", "title"}},
+		{"exported embed is flattened", insertNote{}, []string{"created_by", "updated_by", "body"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fields, err := structFields(tt.v)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, f := range fields {
+				got = append(got, f.column)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("columns mismatch: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryArgsInsertColumns(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	note := insertNote{AuditFields: AuditFields{CreatedBy: "ann"}, Body: "hi"}
+	cols, vals, err := qa.InsertColumns(&note, InsertSkip("body"), InsertSkipZero())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `"created_by"`; cols != want {
+		t.Fatalf("columns mismatch: got %q, want %q", cols, want)
+	}
+	if want := `$1`; vals != want {
+		t.Fatalf("values mismatch: got %q, want %q", vals, want)
+	}
+	if want := []any{"ann"}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+
+	qa = NewQueryArgs(DialectPostgres)
+	cols, vals, err = qa.InsertColumns(taggedPost{ID: 1, Tags: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `"id", "tags"`; cols != want {
+		t.Fatalf("slice field columns mismatch: got %q, want %q", cols, want)
+	}
+	if want := `$1, $2`; vals != want {
+		t.Fatalf("slice field values mismatch: got %q, want %q", vals, want)
+	}
+	if want := []any{1, []string{"a", "b"}}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("slice field args mismatch: got %v, want %v", qa.args, want)
+	}
+
+	type dup struct {
+		AuditFields
+		CreatedBy string `db:"created_by"`
+	}
+	if _, _, err := qa.InsertColumns(dup{}); err == nil || !strings.Contains(err.Error(), "duplicate column") {
+		t.Fatalf("expected duplicate column error, got %v", err)
+	}
+}
+
+func TestRendererInsert(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `{{ $ins := insert .Doc "id" }}INSERT INTO docs ({{ $ins.Columns }}) VALUES ({{ $ins.Values }})`
+
+	r := NewRenderer(DialectOracle)
+	sql, args, err := r.FromString(tmpl, map[string]any{"Doc": insertDoc{ID: 1, Tenant: &Tenant{TenantID: 9}, Title: "t"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `INSERT INTO docs ("tenant_id", "title") VALUES (:1, :2)`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{9, "t"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+}
+
+func TestQueryArgsValuesFields(t *testing.T) {
+	t.Parallel()
+
+	rows := []*insertUser{
+		{ID: 1, Name: "ann", Email: "a@x", Plain: 7},
+		{ID: 2, Name: "bob", Email: "b@x", Plain: 8},
+	}
+
+	qa := NewQueryArgs(DialectPostgres)
+	got := qa.ValuesFields(rows, []string{"email", "id", "Plain"})
+	if want := `($1, $2, $3), ($4, $5, $6)`; got != want {
+		t.Fatalf("values mismatch: got %q, want %q", got, want)
+	}
+	if want := []any{"a@x", 1, 7, "b@x", 2, 8}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+
+	qa = NewQueryArgs(DialectMySQL)
+	if got, want := qa.ValuesFields([1]insertUser{{Name: "cy"}}, []string{"Name"}), "(?)"; got != want {
+		t.Fatalf("field name mismatch: got %q, want %q", got, want)
+	}
+
+	qa = NewQueryArgs(DialectPostgres)
+	posts := []taggedPost{{ID: 1, Tags: []string{"a", "b"}}, {ID: 2}}
+	if got, want := qa.ValuesFields(posts, []string{"id", "tags"}), "($1, $2), ($3, $4)"; got != want {
+		t.Fatalf("slice field mismatch: got %q, want %q", got, want)
+	}
+	if want := []any{1, []string{"a", "b"}, 2, []string(nil)}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("slice field args mismatch: got %v, want %v", qa.args, want)
+	}
+}
+
+func TestRendererValuesFieldsErrors(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `INSERT INTO users (name) VALUES {{ valuesFields .Rows .Fields }}`
+	r := NewRenderer(DialectPostgres)
+
+	tests := []struct {
+		name    string
+		rows    any
+		fields  []string
+		wantErr string
+	}{
+		{"missing field", []insertUser{{}}, []string{"nope"}, `has no field "nope"`},
+		{"skipped field", []insertUser{{}}, []string{"Secret"}, `has no field "Secret"`},
+		{"no fields", []insertUser{{}}, nil, "at least one field"},
+		{"no rows", []insertUser{}, []string{"name"}, "at least one row"},
+		{"not a slice", insertUser{}, []string{"name"}, "expects a slice"},
+		{"not structs", []int{1}, []string{"name"}, "row 0"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := r.FromString(tmpl, map[string]any{"Rows": tt.rows, "Fields": tt.fields})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestQueryArgsValuesAs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		columns  []string
+		rows     any
+		types    []string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{"postgres slices", DialectPostgres, []string{"id", "val"}, [][]any{{1, "a"}, {2, "b"}}, nil,
+			`(VALUES ($1, $2), ($3, $4)) AS "t"("id", "val")`, []any{1, "a", 2, "b"}},
+		{"postgres casts", DialectPostgres, []string{"id", "val"}, [][]any{{1, "a"}}, []string{"int", ""},
+			`(VALUES ($1::int, $2)) AS "t"("id", "val")`, []any{1, "a"}},
+		{"sqlserver casts", DialectSQLServer, []string{"id"}, [][]int{{1}, {2}}, []string{"bigint"},
+			`(VALUES (CAST(@p1 AS bigint)), (CAST(@p2 AS bigint))) AS [t]([id])`, []any{1, 2}},
+		{"mysql rows", DialectMySQL, []string{"id", "name"}, []any{[]any{1, "a"}, [2]any{2, "b"}}, nil,
+			"(VALUES ROW(?, ?), ROW(?, ?)) AS `t`(`id`, `name`)", []any{1, "a", 2, "b"}},
+		{"structs", DialectPostgres, []string{"email", "id"}, []insertUser{{ID: 1, Email: "a@x"}}, nil,
+			`(VALUES ($1, $2)) AS "t"("email", "id")`, []any{"a@x", 1}},
+		{"slice cells", DialectPostgres, []string{"id", "tags"}, [][]any{{1, []string{"a", "b"}}, {2, nil}}, []string{"", "text[]"},
+			`(VALUES ($1, $2::text[]), ($3, $4::text[])) AS "t"("id", "tags")`, []any{1, []string{"a", "b"}, 2, nil}},
+		{"slice struct field", DialectPostgres, []string{"id", "tags"}, []taggedPost{{ID: 1, Tags: []string{"a"}}}, nil,
+			`(VALUES ($1, $2)) AS "t"("id", "tags")`, []any{1, []string{"a"}}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(tt.dialect)
+			if got := qa.ValuesAs("t", tt.columns, tt.rows, tt.types...); got != tt.wantSQL {
+				t.Fatalf("sql mismatch: got %q, want %q", got, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(qa.args, tt.wantArgs) {
+				t.Fatalf("args mismatch: got %v, want %v", qa.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestRendererValuesAsErrors(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM {{ valuesAs .Alias .Cols .Rows }}`
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		alias   string
+		cols    []string
+		rows    any
+		wantErr string
+	}{
+		{"sqlite", DialectSQLite, "t", []string{"id"}, [][]int{{1}}, "not supported"},
+		{"oracle", DialectOracle, "t", []string{"id"}, [][]int{{1}}, "not supported"},
+		{"qualified alias", DialectPostgres, "s.t", []string{"id"}, [][]int{{1}}, "must not be qualified"},
+		{"no columns", DialectPostgres, "t", nil, [][]int{{1}}, "at least one column"},
+		{"no rows", DialectPostgres, "t", []string{"id"}, [][]int{}, "at least one row"},
+		{"short row", DialectPostgres, "t", []string{"id", "val"}, [][]int{{1}}, "row 0 has 1 values for 2 columns"},
+		{"missing field", DialectPostgres, "t", []string{"nope"}, []insertUser{{}}, `has no field "nope"`},
+		{"bad column", DialectPostgres, "t", []string{"id;"}, [][]int{{1}}, "invalid"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewRenderer(tt.dialect)
+			_, _, err := r.FromString(tmpl, map[string]any{"Alias": tt.alias, "Cols": tt.cols, "Rows": tt.rows})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	qa := NewQueryArgs(DialectPostgres)
+	for _, types := range [][]string{{"int"}, {"int; DROP", "text"}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected panic for types %q", types)
+				}
+			}()
+			qa.ValuesAs("t", []string{"id", "val"}, [][]int{{1, 2}}, types...)
+		}()
+	}
+}
+
+func TestQueryArgsSetChanged(t *testing.T) {
+	t.Parallel()
+
+	u := &insertUser{ID: 3, Name: "ann", Email: "a@x", Plain: 9}
+
+	qa := NewQueryArgs(DialectPostgres)
+	got := qa.SetChanged(u, []string{"email", "Name", "Plain"})
+	if want := `"email" = $1, "name" = $2, "Plain" = $3`; got != want {
+		t.Fatalf("set mismatch: got %q, want %q", got, want)
+	}
+	if want := []any{"a@x", "ann", 9}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", qa.args, want)
+	}
+	if got := qa.SetChanged(u, nil); got != "" {
+		t.Fatalf("expected empty output, got %q", got)
+	}
+
+	qa = NewQueryArgs(DialectPostgres)
+	got = qa.SetChanged(taggedPost{ID: 1, Tags: []string{"a", "b"}}, []string{"tags"})
+	if want := `"tags" = $1`; got != want {
+		t.Fatalf("slice field set mismatch: got %q, want %q", got, want)
+	}
+	if want := []any{[]string{"a", "b"}}; !reflect.DeepEqual(qa.args, want) {
+		t.Fatalf("slice field args mismatch: got %v, want %v", qa.args, want)
+	}
+}
+
+func TestRendererSetChanged(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `UPDATE users SET {{ setChanged .User .Changed }} WHERE id = {{ bind .User.ID }}`
+	r := NewRenderer(DialectMySQL)
+
+	sql, args, err := r.FromString(tmpl, map[string]any{"User": insertUser{ID: 3, Name: "bo"}, "Changed": []string{"name"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "UPDATE users SET `name` = ? WHERE id = ?"; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+	if want := []any{"bo", 3}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	for _, changed := range [][]string{{"nope"}, {"Secret"}, {"internal"}} {
+		_, _, err := r.FromString(tmpl, map[string]any{"User": insertUser{}, "Changed": changed})
+		if err == nil || !strings.Contains(err.Error(), "has no field") {
+			t.Fatalf("expected unknown field error for %v, got %v", changed, err)
+		}
+	}
+	if _, _, err := r.FromString(`{{ setChanged .User .Changed }}`, map[string]any{"User": 1, "Changed": []string{"a"}}); err == nil {
+		t.Fatal("expected error for non-struct")
+	}
+}
@@ -0,0 +1,164 @@
+package sqlrender
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UpsertWhereChanged renders a change-detection guard for the update branch of
+// an upsert, so conflicting rows are only rewritten when at least one of the
+// listed columns actually differs from the proposed value. table names the
+// upsert target as referenced in the statement (its name or alias).
+//
+// The guard is emitted as a trailing `WHERE (...)` for the dialects whose
+// upsert syntax accepts one (Postgres `ON CONFLICT ... DO UPDATE` and SQLite
+// `ON CONFLICT ... DO UPDATE`). Other dialects return an error: MySQL's
+// `ON DUPLICATE KEY UPDATE` has no WHERE, while SQL Server, Oracle and
+// Snowflake need the condition on a MERGE `WHEN MATCHED AND ...` branch.
+func (qa *QueryArgs) UpsertWhereChanged(table string, columns ...string) (string, error) {
+	var excluded string
+	switch qa.dialect {
+	case DialectPostgres:
+		excluded = "EXCLUDED"
+	case DialectSQLite:
+		excluded = "excluded"
+	default:
+		return "", fmt.Errorf("sqlrender: upsert change guard is not supported for dialect %q", qa.dialect)
+	}
+
+	if len(columns) == 0 {
+		return "", fmt.Errorf("sqlrender: upsert change guard requires at least one column")
+	}
+
+	target, err := qa.identifier(table)
+	if err != nil {
+		return "", err
+	}
+
+	conds := make([]string, len(columns))
+	for i, col := range columns {
+		quoted, err := qa.identifier(col)
+		if err != nil {
+			return "", err
+		}
+		conds[i] = qa.distinctFrom(target+"."+quoted, excluded+"."+quoted)
+	}
+
+	return "WHERE (" + strings.Join(conds, " OR ") + ")", nil
+}
+
+// OnConflict renders the trailing upsert clause of an INSERT for the
+// dialect, quoting every column:
+//
+//   - Postgres: `ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`
+//   - SQLite: the same, with lowercase `excluded`
+//   - MySQL: `ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)`; the conflict
+//     columns are implied by the table's unique keys and are only used for
+//     the no-op update when updateCols is empty. With a row alias set by
+//     SetMySQLRowAlias (MySQL 8.0.19+, where VALUES() is deprecated) it is
+//     AS `new` ON DUPLICATE KEY UPDATE `name` = `new`.`name`, which must
+//     directly follow the VALUES list
+//
+// With no updateCols, Postgres and SQLite render `DO NOTHING`. SQL Server,
+// Oracle and Snowflake have no trailing upsert clause and return an error;
+// use a MERGE template there. On Postgres and SQLite, append
+// upsertWhereChanged to skip rewriting unchanged rows.
+func (qa *QueryArgs) OnConflict(conflictCols, updateCols []string) (string, error) {
+	conflict, err := qa.identifiers(conflictCols)
+	if err != nil {
+		return "", err
+	}
+	update, err := qa.identifiers(updateCols)
+	if err != nil {
+		return "", err
+	}
+
+	switch qa.dialect {
+	case DialectPostgres, DialectSQLite:
+		excluded := "EXCLUDED"
+		if qa.dialect == DialectSQLite {
+			excluded = "excluded"
+		}
+
+		clause := "ON CONFLICT"
+		if len(conflict) > 0 {
+			clause += " (" + strings.Join(conflict, ", ") + ")"
+		}
+		if len(update) == 0 {
+			return clause + " DO NOTHING", nil
+		}
+		if len(conflict) == 0 {
+			return "", fmt.Errorf("sqlrender: onConflict DO UPDATE requires conflict columns")
+		}
+
+		sets := make([]string, len(update))
+		for i, col := range update {
+			sets[i] = col + " = " + excluded + "." + col
+		}
+		return clause + " DO UPDATE SET " + strings.Join(sets, ", "), nil
+	case DialectMySQL:
+		if len(update) == 0 {
+			if len(conflict) == 0 {
+				return "", fmt.Errorf("sqlrender: onConflict requires conflict or update columns")
+			}
+			// Assigning a key column to itself turns the insert into a no-op.
+			return "ON DUPLICATE KEY UPDATE " + conflict[0] + " = " + conflict[0], nil
+		}
+
+		if qa.mysqlRowAlias != "" {
+			if strings.Contains(qa.mysqlRowAlias, ".") {
+				return "", fmt.Errorf("sqlrender: invalid MySQL row alias %q", qa.mysqlRowAlias)
+			}
+			alias, err := qa.identifier(qa.mysqlRowAlias)
+			if err != nil {
+				return "", err
+			}
+
+			sets := make([]string, len(update))
+			for i, col := range update {
+				sets[i] = col + " = " + alias + "." + col
+			}
+			return "AS " + alias + " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "), nil
+		}
+
+		sets := make([]string, len(update))
+		for i, col := range update {
+			sets[i] = col + " = VALUES(" + col + ")"
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "), nil
+	default:
+		return "", fmt.Errorf("sqlrender: onConflict is not supported for dialect %q; use a MERGE statement", qa.dialect)
+	}
+}
+
+// SetMySQLRowAlias makes onConflict use MySQL 8.0.19's row alias syntax,
+// `AS alias ON DUPLICATE KEY UPDATE c = alias.c`, instead of the VALUES(c)
+// function deprecated in 8.0.20. An empty alias (the default) keeps VALUES()
+// for older servers and MariaDB.
+func (r *Renderer) SetMySQLRowAlias(alias string) *Renderer {
+	r.mysqlRowAlias = alias
+	return r
+}
+
+// identifiers validates and quotes every name.
+func (qa *QueryArgs) identifiers(names []string) ([]string, error) {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		q, err := qa.identifier(name)
+		if err != nil {
+			return nil, err
+		}
+		quoted[i] = q
+	}
+	return quoted, nil
+}
+
+// distinctFrom renders a NULL-safe inequality between two expressions for
+// the dialects UpsertWhereChanged supports: `IS NOT` on SQLite and
+// `IS DISTINCT FROM` on Postgres.
+func (qa *QueryArgs) distinctFrom(a, b string) string {
+	if qa.dialect == DialectSQLite {
+		return a + " IS NOT " + b
+	}
+	return a + " IS DISTINCT FROM " + b
+}
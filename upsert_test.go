@@ -0,0 +1,193 @@
+package sqlrender
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryArgsUpsertWhereChanged(t *testing.T) {
+	t.Parallel()
+
+	qa := NewQueryArgs(DialectPostgres)
+	got, err := qa.UpsertWhereChanged("users", "name", "email")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `WHERE ("users"."name" IS DISTINCT FROM EXCLUDED."name" OR "users"."email" IS DISTINCT FROM EXCLUDED."email")`
+	if got != want {
+		t.Fatalf("guard mismatch: got %q, want %q", got, want)
+	}
+
+	qa = NewQueryArgs(DialectSQLite)
+	got, err = qa.UpsertWhereChanged("users", "name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `WHERE ("users"."name" IS NOT excluded."name")`; got != want {
+		t.Fatalf("guard mismatch: got %q, want %q", got, want)
+	}
+}
+
+func TestQueryArgsUpsertWhereChangedErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		table   string
+		columns []string
+	}{
+		{"mysql", DialectMySQL, "users", []string{"name"}},
+		{"sqlserver", DialectSQLServer, "users", []string{"name"}},
+		{"no columns", DialectPostgres, "users", nil},
+		{"invalid column", DialectPostgres, "users", []string{"name;"}},
+		{"invalid table", DialectPostgres, "", []string{"name"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			qa := NewQueryArgs(tt.dialect)
+			if got, err := qa.UpsertWhereChanged(tt.table, tt.columns...); err == nil {
+				t.Fatalf("expected error, got %q", got)
+			}
+		})
+	}
+}
+
+func TestQueryArgsDistinctFrom(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectPostgres, "a IS DISTINCT FROM b"},
+		{DialectSQLite, "a IS NOT b"},
+	}
+
+	for _, tt := range tests {
+		if got := NewQueryArgs(tt.dialect).distinctFrom("a", "b"); got != tt.want {
+			t.Fatalf("%s: got %q, want %q", tt.dialect, got, tt.want)
+		}
+	}
+}
+
+func TestRendererUpsertWhereChanged(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	sql, _, err := r.FromString(
+		`INSERT INTO users (id, name) VALUES ({{ bind .ID }}, {{ bind .Name }}) `+
+			`ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name {{ upsertWhereChanged "users" "name" }}`,
+		map[string]any{"ID": 1, "Name": "a"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name ` +
+		`WHERE ("users"."name" IS DISTINCT FROM EXCLUDED."name")`
+	if sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
+
+func TestQueryArgsOnConflict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		conflict []string
+		update   []string
+		want     string
+		wantErr  bool
+	}{
+		{"postgres update", DialectPostgres, []string{"tenant_id", "email"}, []string{"name", "updated_at"},
+			`ON CONFLICT ("tenant_id", "email") DO UPDATE SET "name" = EXCLUDED."name", "updated_at" = EXCLUDED."updated_at"`, false},
+		{"postgres nothing", DialectPostgres, []string{"id"}, nil, `ON CONFLICT ("id") DO NOTHING`, false},
+		{"postgres nothing without target", DialectPostgres, nil, nil, `ON CONFLICT DO NOTHING`, false},
+		{"postgres update without target", DialectPostgres, nil, []string{"name"}, "", true},
+		{"postgres invalid column", DialectPostgres, []string{"id;"}, nil, "", true},
+		{"sqlite update", DialectSQLite, []string{"id"}, []string{"name"}, `ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name"`, false},
+		{"mysql update", DialectMySQL, []string{"id"}, []string{"name", "email"},
+			"ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `email` = VALUES(`email`)", false},
+		{"mysql nothing", DialectMySQL, []string{"id"}, nil, "ON DUPLICATE KEY UPDATE `id` = `id`", false},
+		{"mysql no columns", DialectMySQL, nil, nil, "", true},
+		{"sqlserver", DialectSQLServer, []string{"id"}, []string{"name"}, "", true},
+		{"oracle", DialectOracle, []string{"id"}, []string{"name"}, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewQueryArgs(tt.dialect).OnConflict(tt.conflict, tt.update)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("clause mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererOnConflictMySQLRowAlias(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectMySQL).SetMySQLRowAlias("new")
+	out, args, err := r.FromString(
+		`INSERT INTO users (id, name) VALUES ({{ bind .ID }}, {{ bind .Name }}) {{ onConflict .Keys .Updates }}`,
+		map[string]any{"ID": 1, "Name": "a", "Keys": []string{"id"}, "Updates": []string{"name", "email"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "INSERT INTO users (id, name) VALUES (?, ?) AS `new` ON DUPLICATE KEY UPDATE `name` = `new`.`name`, `email` = `new`.`email`"
+	if out != want {
+		t.Fatalf("sql mismatch: got %q, want %q", out, want)
+	}
+	if !reflect.DeepEqual(args, []any{1, "a"}) {
+		t.Fatalf("args mismatch: got %v", args)
+	}
+
+	out, _, err = r.FromString(`{{ onConflict .Keys nil }}`, map[string]any{"Keys": []string{"id"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "ON DUPLICATE KEY UPDATE `id` = `id`"; out != want {
+		t.Fatalf("no-op sql mismatch: got %q, want %q", out, want)
+	}
+
+	bad := NewRenderer(DialectMySQL).SetMySQLRowAlias("t.new")
+	if _, _, err := bad.FromString(`{{ onConflict .Keys .Keys }}`, map[string]any{"Keys": []string{"id"}}); err == nil {
+		t.Fatal("expected error for qualified row alias")
+	}
+}
+
+func TestRendererOnConflictWithChangeGuard(t *testing.T) {
+	t.Parallel()
+
+	r := NewRenderer(DialectPostgres)
+	sql, _, err := r.FromString(
+		`INSERT INTO users (id, name) VALUES ({{ bind .ID }}, {{ bind .Name }}) {{ onConflict .Keys .Updates }} {{ upsertWhereChanged "users" "name" }}`,
+		map[string]any{"ID": 1, "Name": "a", "Keys": []string{"id"}, "Updates": []string{"name"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name" ` +
+		`WHERE ("users"."name" IS DISTINCT FROM EXCLUDED."name")`
+	if sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
@@ -0,0 +1,23 @@
+package sqlrender
+
+import "reflect"
+
+// defaultValue returns value unless it is nil or the zero value of its type,
+// in which case it returns fallback. It backs the `default` template func:
+// `{{ bind (default .Limit 50) }}`.
+func defaultValue(value, fallback any) any {
+	return coalesceValue(value, fallback)
+}
+
+// coalesceValue returns the first argument that is neither nil nor the zero
+// value of its type, or nil when there is none. Pointers count by their own
+// value, so a non-nil pointer to a zero value is returned. It backs the
+// `coalesceVal` template func and binds nothing.
+func coalesceValue(values ...any) any {
+	for _, v := range values {
+		if v != nil && !reflect.ValueOf(v).IsZero() {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package sqlrender
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoalesceValue(t *testing.T) {
+	t.Parallel()
+
+	zero := 0
+	tests := []struct {
+		name   string
+		values []any
+		want   any
+	}{
+		{"none", nil, nil},
+		{"all zero", []any{nil, "", 0, false, []int(nil)}, nil},
+		{"first non-zero", []any{"", "a", "b"}, "a"},
+		{"typed nil skipped", []any{(*int)(nil), 3}, 3},
+		{"pointer to zero kept", []any{&zero, 3}, &zero},
+		{"empty slice kept", []any{[]int{}, 3}, []int{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := coalesceValue(tt.values...); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("coalesce mismatch: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererDefaultAndCoalesceVal(t *testing.T) {
+	t.Parallel()
+
+	const tmpl = `SELECT * FROM t WHERE region = {{ bind (coalesceVal .Region .OrgRegion "eu") }} LIMIT {{ bind (default .Limit 50) }}`
+
+	r := NewRenderer(DialectMySQL)
+	_, args, err := r.FromString(tmpl, map[string]any{"Region": "", "OrgRegion": "us", "Limit": 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []any{"us", 50}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	_, args, err = r.FromString(tmpl, map[string]any{"Limit": 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []any{"eu", 10}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args mismatch: got %v, want %v", args, want)
+	}
+
+	r.AddFunc("default", func(fallback, value any) any { return fallback })
+	_, args, err = r.FromString(`{{ bind (default 1 2) }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []any{1}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("custom default should win: got %v, want %v", args, want)
+	}
+}
@@ -0,0 +1,77 @@
+package sqlrender
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VerifyPlaceholders checks that the placeholders in sql line up with args
+// for dialect, catching helpers that bind a value but drop its placeholder
+// (or the reverse). Literals, quoted identifiers and comments are ignored.
+// For numbered dialects every index from 1 to len(args) must appear and no
+// other index may, except that sql.NamedArg arguments (from bindNamed on
+// Oracle) are referenced by name and need no index; for "?" dialects the
+// counts must match. Registered
+// dialects are checked the same way, based on the shape of their
+// placeholders.
+func VerifyPlaceholders(sql string, args []any, dialect Dialect) error {
+	return NewQueryArgs(dialect).verifyPlaceholders(sql, args)
+}
+
+// verifyPlaceholders implements VerifyPlaceholders using qa's placeholder
+// format, which honours a renderer's placeholder style override.
+func (qa *QueryArgs) verifyPlaceholders(query string, args []any) error {
+	dialect := qa.dialect
+	first, second := qa.placeholderFor(1), qa.placeholderFor(2)
+
+	var text strings.Builder
+	for _, tok := range scanSQL(query, dialect) {
+		if tok.kind == tokenText {
+			text.WriteString(tok.text)
+		}
+		// Keep token boundaries from joining into a placeholder.
+		text.WriteByte(' ')
+	}
+
+	if first == second {
+		if n := strings.Count(text.String(), first); n != len(args) {
+			return fmt.Errorf("sqlrender: %s placeholder count mismatch: sql has %d, args has %d", dialect, n, len(args))
+		}
+		return nil
+	}
+
+	pattern, err := placeholderPattern(first)
+	if err != nil {
+		return fmt.Errorf("sqlrender: cannot verify placeholders for %s: %w", dialect, err)
+	}
+	seen := make(map[int]bool)
+	for _, m := range pattern.FindAllStringSubmatch(text.String(), -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return fmt.Errorf("sqlrender: invalid placeholder %q: %w", m[0], err)
+		}
+		if n < 1 || n > len(args) {
+			return fmt.Errorf("sqlrender: placeholder %s has no argument (%d args bound)", m[0], len(args))
+		}
+		seen[n] = true
+	}
+	for n := 1; n <= len(args); n++ {
+		if _, named := args[n-1].(sql.NamedArg); !seen[n] && !named {
+			return fmt.Errorf("sqlrender: argument %d is never referenced (missing placeholder %s)", n, qa.placeholderFor(n))
+		}
+	}
+	return nil
+}
+
+// placeholderPattern derives a matcher for numbered placeholders from the
+// rendering of placeholder 1, e.g. "$1" or "@p1".
+func placeholderPattern(first string) (*regexp.Regexp, error) {
+	prefix, suffix, ok := strings.Cut(first, "1")
+	if !ok {
+		return nil, fmt.Errorf("placeholder %q is not numbered", first)
+	}
+	return regexp.Compile(regexp.QuoteMeta(prefix) + `(\d+)` + regexp.QuoteMeta(suffix))
+}
@@ -0,0 +1,145 @@
+package sqlrender
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestVerifyPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		sql     string
+		args    []any
+		wantErr string
+	}{
+		{name: "postgres ok", dialect: DialectPostgres, sql: "SELECT $1, $2::uuid", args: []any{1, 2}},
+		{name: "postgres reuse", dialect: DialectPostgres, sql: "WHERE a = $1 OR b = $1", args: []any{1}},
+		{name: "postgres gap", dialect: DialectPostgres, sql: "SELECT $2", args: []any{1, 2}, wantErr: "argument 1 is never referenced"},
+		{name: "postgres extra", dialect: DialectPostgres, sql: "SELECT $1, $2", args: []any{1}, wantErr: "placeholder $2 has no argument"},
+		{name: "postgres in string", dialect: DialectPostgres, sql: "SELECT '$1' -- $1", args: []any{1}, wantErr: "never referenced"},
+		{name: "sqlserver ok", dialect: DialectSQLServer, sql: "SELECT @p1, @p10", args: make([]any, 10), wantErr: "argument 2"},
+		{name: "oracle ok", dialect: DialectOracle, sql: "SELECT :1 FROM dual WHERE x = '12:30'", args: []any{1}},
+		{name: "mysql ok", dialect: DialectMySQL, sql: "SELECT ?, '?', `?` FROM t WHERE a = ?", args: []any{1, 2}},
+		{name: "mysql count", dialect: DialectMySQL, sql: "SELECT ?", args: []any{1, 2}, wantErr: "sql has 1, args has 2"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifyPlaceholders(tt.sql, tt.args, tt.dialect)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error mismatch: got %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyPlaceholdersRegisteredDialect(t *testing.T) {
+	t.Parallel()
+
+	const d Dialect = "verify-test"
+	RegisterDialect(d, DialectSpec{Placeholder: func(n int) string { return fmt.Sprintf("#%d#", n) }})
+
+	if err := VerifyPlaceholders("SELECT #1#, #2#", []any{1, 2}, d); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyPlaceholders("SELECT #2#", []any{1, 2}, d); err == nil {
+		t.Fatal("expected error for missing placeholder")
+	}
+}
+
+// TestBindOnlyWhenEmitted shows why helpers must bind lazily: a helper that
+// binds up front and then drops the placeholder desyncs SQL and args, which
+// VerifyPlaceholders reports, while binding inside the emitting branch stays
+// aligned.
+func TestBindOnlyWhenEmitted(t *testing.T) {
+	t.Parallel()
+
+	tmpl := `SELECT * FROM t WHERE a = {{ bind .A }}{{ optional "b" .B }}`
+	data := map[string]any{"A": 1, "B": 0}
+
+	render := func(optional func(qa *QueryArgs) any) (string, []any) {
+		t.Helper()
+		r := NewRenderer(DialectPostgres)
+		qa := r.newQueryArgs(DialectPostgres)
+		r.AddFunc("optional", optional(qa))
+		sql, err := r.render(tmpl, data, qa)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return sql, qa.args
+	}
+
+	sql, args := render(func(qa *QueryArgs) any {
+		return func(col string, v int) string {
+			ph := qa.Bind(v) // bound even when the clause is dropped
+			if v == 0 {
+				return ""
+			}
+			return " AND " + col + " = " + ph
+		}
+	})
+	if err := VerifyPlaceholders(sql, args, DialectPostgres); err == nil {
+		t.Fatalf("expected mismatch for eager bind, sql %q args %v", sql, args)
+	}
+
+	sql, args = render(func(qa *QueryArgs) any {
+		return func(col string, v int) string {
+			if v == 0 {
+				return ""
+			}
+			return " AND " + col + " = " + qa.Bind(v)
+		}
+	})
+	if err := VerifyPlaceholders(sql, args, DialectPostgres); err != nil {
+		t.Fatalf("unexpected error for lazy bind: %v (sql %q args %v)", err, sql, args)
+	}
+}
+
+func TestRendererSetStrictArgs(t *testing.T) {
+	t.Parallel()
+
+	// dropBind binds its argument but discards the placeholder, the bug
+	// strict mode is meant to catch.
+	newRenderer := func() (*Renderer, *QueryArgs) {
+		r := NewRenderer(DialectSQLServer)
+		qa := r.newQueryArgs(DialectSQLServer)
+		r.AddFunc("dropBind", func(v any) string { qa.Bind(v); return "" })
+		return r, qa
+	}
+	const tmpl = `SELECT * FROM t WHERE a = {{ bind .A }}{{ dropBind .B }}`
+	data := map[string]any{"A": 1, "B": 2}
+
+	r, qa := newRenderer()
+	if _, err := r.render(tmpl, data, qa); err != nil {
+		t.Fatalf("non-strict render should succeed: %v", err)
+	}
+
+	r, qa = newRenderer()
+	r.SetStrictArgs(true)
+	_, err := r.render(tmpl, data, qa)
+	if err == nil || !strings.Contains(err.Error(), "argument 2 is never referenced") {
+		t.Fatalf("expected strict args error, got %v", err)
+	}
+
+	sql, _, err := NewRenderer(DialectSQLServer).SetStrictArgs(true).SetMinify(true).
+		FromString("SELECT {{ bind .A }} -- @p9 in a comment\n, '@p2'", data)
+	if err != nil {
+		t.Fatalf("unexpected error for consistent render: %v", err)
+	}
+	if want := `SELECT @p1 , '@p2'`; sql != want {
+		t.Fatalf("sql mismatch: got %q, want %q", sql, want)
+	}
+}
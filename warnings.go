@@ -0,0 +1,29 @@
+package sqlrender
+
+import "fmt"
+
+// oracleMaxInList is the number of expressions Oracle accepts in one IN list
+// (ORA-01795).
+const oracleMaxInList = 1000
+
+// SetWarningsEnabled makes renders record non-fatal diagnostics, such as an
+// empty list bound as (NULL) or an argument count close to the dialect's
+// limit. They are exposed by Statement.Warnings and never fail the render.
+// It is off by default.
+func (r *Renderer) SetWarningsEnabled(on bool) *Renderer {
+	r.warningsEnabled = on
+	return r
+}
+
+// Warnings returns the diagnostics recorded so far, in the order they were
+// raised. It is always empty unless warnings are enabled.
+func (qa *QueryArgs) Warnings() []string {
+	return qa.warnings
+}
+
+// warnf records a warning when warnings are enabled.
+func (qa *QueryArgs) warnf(format string, args ...any) {
+	if qa.warningsEnabled {
+		qa.warnings = append(qa.warnings, fmt.Sprintf(format, args...))
+	}
+}
@@ -0,0 +1,82 @@
+package sqlrender
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRendererWarnings(t *testing.T) {
+	t.Parallel()
+
+	tmpl := `SELECT * FROM t WHERE id IN {{ bind .IDs }} AND kind IN {{ bind .Kinds }}`
+	data := map[string]any{"IDs": []int{}, "Kinds": []string{"a"}}
+
+	stmt, err := NewRenderer(DialectPostgres).RenderString(tmpl, data, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stmt.Warnings() != nil {
+		t.Fatalf("expected no warnings by default, got %v", stmt.Warnings())
+	}
+
+	stmt, err = NewRenderer(DialectPostgres).SetWarningsEnabled(true).RenderString(tmpl, data, DialectPostgres)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT * FROM t WHERE id IN (NULL) AND kind IN ($1)"; stmt.SQL != want {
+		t.Fatalf("sql mismatch: got %q, want %q", stmt.SQL, want)
+	}
+	if want := []string{"empty list expanded to (NULL)"}; !reflect.DeepEqual(stmt.Warnings(), want) {
+		t.Fatalf("warnings mismatch: got %v, want %v", stmt.Warnings(), want)
+	}
+}
+
+func TestQueryArgsWarnings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		bind    func(qa *QueryArgs)
+		want    string
+	}{
+		{"near arg limit", DialectSQLServer, func(qa *QueryArgs) {
+			for i := 0; i < 1900; i++ {
+				qa.Bind(i)
+			}
+		}, "bound 1890 arguments for sqlserver, near its limit of 2100"},
+		{"oracle in list", DialectOracle, func(qa *QueryArgs) {
+			qa.Bind(make([]int, 1001))
+		}, "bound a list of 1001 values; Oracle allows at most 1000 per IN list, use inChunked"},
+		{"oracle in predicate", DialectOracle, func(qa *QueryArgs) {
+			qa.In("id", make([]int, 1001))
+		}, "bound a list of 1001 values; Oracle allows at most 1000 per IN list, use inChunked"},
+		{"oracle not in predicate", DialectOracle, func(qa *QueryArgs) {
+			qa.NotIn("id", make([]int, 1001))
+		}, "bound a list of 1001 values; Oracle allows at most 1000 per IN list, use inChunked"},
+		{"oracle chunked in list", DialectOracle, func(qa *QueryArgs) {
+			qa.InChunked("id", make([]int, 1001), 1000)
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			qa := NewQueryArgs(tt.dialect)
+			tt.bind(qa)
+			if len(qa.Warnings()) != 0 {
+				t.Fatalf("expected no warnings when disabled, got %v", qa.Warnings())
+			}
+
+			qa = NewQueryArgs(tt.dialect)
+			qa.warningsEnabled = true
+			tt.bind(qa)
+			if got := strings.Join(qa.Warnings(), "; "); got != tt.want {
+				t.Fatalf("warnings mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}